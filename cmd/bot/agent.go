@@ -0,0 +1,216 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// agentCommandKind 表示技术支持在工单话题内的命令类型。
+type agentCommandKind int
+
+const (
+	agentClaim    agentCommandKind = iota + 1 // 认领
+	agentAssign                               // 指派给 @某人
+	agentPriority                             // 设置优先级
+	agentResolve                              // 标记已解决
+	agentReopen                               // 重新打开
+	agentTag                                  // 添加 / 删除标签
+	agentNeedInfo                             // 请用户补充信息
+	agentSLA                                  // 查看 SLA 状态和超时计数
+	agentCSAT                                 // 查看满意度统计
+	agentMerge                                // 作为重复问题合并到其他工单
+	agentOnCall                               // 查看当前值班人员
+	agentAway                                 // 设置自己离开（不参与自动分配）
+	agentBack                                 // 设置自己回来
+	agentHelp                                 // 命令帮助
+)
+
+// agentCommandVerbs 是命令关键词（忽略大小写）到命令类型的映射。
+var agentCommandVerbs = map[string]agentCommandKind{
+	"claim": agentClaim, "认领": agentClaim,
+	"assign": agentAssign, "指派": agentAssign, "分配": agentAssign,
+	"priority": agentPriority, "prio": agentPriority, "优先级": agentPriority,
+	"resolve": agentResolve, "resolved": agentResolve, "解决": agentResolve, "已解决": agentResolve,
+	"reopen": agentReopen, "重新打开": agentReopen, "重开": agentReopen,
+	"tag": agentTag, "tags": agentTag, "标签": agentTag,
+	"need": agentNeedInfo, "needinfo": agentNeedInfo, "补充": agentNeedInfo,
+	"sla": agentSLA, "时效": agentSLA,
+	"csat": agentCSAT, "满意度": agentCSAT,
+	"oncall": agentOnCall, "on-call": agentOnCall, "值班": agentOnCall,
+	"away": agentAway, "离开": agentAway, "暂离": agentAway,
+	"back": agentBack, "回来": agentBack, "返回": agentBack,
+	"merge": agentMerge, "dup": agentMerge, "duplicate": agentMerge, "合并": agentMerge, "重复": agentMerge,
+	"help": agentHelp, "帮助": agentHelp,
+}
+
+// agentHelpText 是话题内命令的帮助信息。
+const agentHelpText = `可用命令 / Commands（@机器人 + 命令）：
+- claim / 认领
+- assign @someone / 指派 @某人
+- priority P0~P3 / 优先级 P1
+- resolve / 解决
+- reopen / 重新打开
+- tag a b -c / 标签 a b -c（"-" 前缀表示删除）
+- need 字段… 日志 / 补充 戒指SN 日志（请用户补充信息）
+- sla / 时效（SLA 状态和累计超时次数）
+- csat / 满意度（按周和处理人的满意度统计）
+- merge T000045 / 合并 T000045（本工单作为重复问题合并到 T000045）
+- oncall / 值班（本工单路由当前的值班人员）
+- away / 离开、back / 回来（暂停 / 恢复自动分配给自己）`
+
+// agentCommand 是解析后的话题内命令。
+type agentCommand struct {
+	kind agentCommandKind
+	args []string
+}
+
+// parseAgentCommand 解析 @机器人 之后的命令文本（第一个词为命令，其余为参数）。
+func parseAgentCommand(content string) (agentCommand, bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 {
+		return agentCommand{kind: agentHelp}, true
+	}
+	kind, ok := agentCommandVerbs[strings.ToLower(fields[0])]
+	if !ok {
+		return agentCommand{}, false
+	}
+	return agentCommand{kind: kind, args: fields[1:]}, true
+}
+
+// handleAgentCommand 执行技术支持在工单话题内 @机器人 的命令：更新工单、编辑根消息，
+// 状态变化时通知用户，并在话题内回复执行结果。
+func (h *wrappedMessageHandler) handleAgentCommand(ctx context.Context, msg feishu.ThreadMessage, t *models.Ticket) error {
+	reply := func(text string) error {
+		return h.feishuClient.ReplyTextInThread(ctx, t.RootMsgID, text)
+	}
+
+	if msg.SenderID == t.UserID {
+		return reply("仅技术支持可以使用工单命令。/ Only the support team can use ticket commands.")
+	}
+	cmd, ok := parseAgentCommand(msg.Content)
+	if !ok {
+		return reply(fmt.Sprintf("无法识别的命令「%s」。/ Unknown command.\n\n%s", msg.Content, agentHelpText))
+	}
+
+	// 已合并的工单在主工单中跟进，不再单独认领、指派、解决或重新打开
+	if t.Status == models.TicketMerged {
+		switch cmd.kind {
+		case agentClaim, agentAssign, agentResolve, agentReopen:
+			return reply(fmt.Sprintf("%s 已合并到 %s，请在 %s 话题中操作。/ %s has been merged into %s, please use that thread.",
+				t.ID, t.MergedInto, t.MergedInto, t.ID, t.MergedInto))
+		}
+	}
+
+	prevStatus := t.Status
+	var result string
+	switch cmd.kind {
+	case agentHelp:
+		return reply(agentHelpText)
+
+	case agentNeedInfo:
+		return h.requestSupplement(ctx, msg, t, cmd.args, reply)
+
+	case agentSLA:
+		return reply(h.buildSLAReport(ctx, t))
+
+	case agentCSAT:
+		return reply(h.buildCSATReport(ctx))
+
+	case agentMerge:
+		return h.mergeTicket(ctx, msg, t, cmd.args, reply)
+
+	case agentOnCall:
+		return reply(h.buildOnCallReport([]string{t.Route}))
+
+	case agentAway, agentBack:
+		return reply(h.setAway(ctx, msg.SenderID, cmd.kind == agentAway))
+
+	case agentClaim:
+		if t.Status == models.TicketResolved {
+			return reply("工单已解决，请先 reopen。/ The ticket is resolved, reopen it first.")
+		}
+		t.Assignee = msg.SenderID
+		t.Status = models.TicketInProgress
+		result = fmt.Sprintf("✅ %s 已由 %s 认领 / claimed", t.ID, atText(msg.SenderID))
+
+	case agentAssign:
+		if len(msg.Mentions) == 0 {
+			return reply("请 @ 要指派的人，例如「assign @张三」。/ Please @ the assignee.")
+		}
+		if t.Status == models.TicketResolved {
+			return reply("工单已解决，请先 reopen。/ The ticket is resolved, reopen it first.")
+		}
+		t.Assignee = msg.Mentions[0].OpenID
+		t.Status = models.TicketInProgress
+		result = fmt.Sprintf("✅ %s 已指派给 %s / assigned", t.ID, atText(t.Assignee))
+
+	case agentPriority:
+		if len(cmd.args) == 0 {
+			return reply("请指定优先级：" + strings.Join(models.TicketPriorities, " / "))
+		}
+		priority, ok := models.NormalizePriority(cmd.args[0])
+		if !ok {
+			return reply("无效的优先级，可选：" + strings.Join(models.TicketPriorities, " / "))
+		}
+		t.Priority = priority
+		result = fmt.Sprintf("✅ %s 优先级 / Priority: %s", t.ID, priority)
+
+	case agentResolve:
+		if t.Status == models.TicketResolved {
+			return reply("工单已是已解决状态。/ The ticket is already resolved.")
+		}
+		t.Status = models.TicketResolved
+		t.ResolvedAt = time.Now()
+		t.ResolvedBy = msg.SenderID
+		result = fmt.Sprintf("✅ %s 已解决 / Resolved", t.ID)
+
+	case agentReopen:
+		if t.Status != models.TicketResolved {
+			return reply("工单未解决，无需重新打开。/ The ticket is not resolved.")
+		}
+		t.Reopen()
+		result = fmt.Sprintf("✅ %s 已重新打开 / Reopened", t.ID)
+
+	case agentTag:
+		if len(cmd.args) == 0 {
+			return reply("请指定标签，例如「tag 蓝牙 固件」，「-标签」表示删除。/ Please specify tags.")
+		}
+		for _, tag := range cmd.args {
+			if strings.HasPrefix(tag, "-") {
+				t.RemoveTag(strings.TrimPrefix(tag, "-"))
+			} else {
+				t.AddTag(tag)
+			}
+		}
+		result = fmt.Sprintf("✅ %s 标签 / Tags: %s", t.ID, strings.Join(t.Tags, "、"))
+	}
+
+	t.UpdatedAt = time.Now()
+	if err := h.tickets.Save(ctx, t); err != nil {
+		log.Printf("[Agent] Failed to save ticket %s: %v", t.ID, err)
+		_ = reply("抱歉，更新工单失败，请稍后重试。/ Failed to update the ticket, please try again later.")
+		return err
+	}
+	log.Printf("[Agent] Ticket %s updated by %s: status=%s, assignee=%s, priority=%s", t.ID, msg.SenderID, t.Status, t.Assignee, t.Priority)
+
+	_ = h.escalationHandler.UpdateTicketMessage(ctx, t)
+	if t.Status != prevStatus {
+		_ = h.escalationHandler.NotifyStatus(ctx, t)
+		if t.Status == models.TicketResolved {
+			h.sendCSATCard(ctx, t)
+			h.notifyDuplicates(ctx, t)
+		}
+	}
+	return reply(result)
+}
+
+// atText 返回文本消息中 @用户 的标记。
+func atText(openID string) string {
+	return fmt.Sprintf(`<at user_id="%s"></at>`, openID)
+}
@@ -0,0 +1,27 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// setAway 设置技术支持离开或回来（离开期间不参与自动分配），返回回复文本。
+func (h *wrappedMessageHandler) setAway(ctx context.Context, userID string, away bool) string {
+	if err := h.tickets.SetAway(ctx, userID, away); err != nil {
+		log.Printf("[Assign] Failed to set away=%v for %s: %v", away, userID, err)
+		return "抱歉，设置失败，请稍后重试。/ Failed to update your status, please try again later."
+	}
+	log.Printf("[Assign] %s set away=%v", userID, away)
+
+	var text string
+	if away {
+		text = fmt.Sprintf("🏖 %s 已设置为离开，新工单不会自动分配给您，回来后请发送 back。/ You're marked away and won't get new tickets until you send \"back\".", atText(userID))
+	} else {
+		text = fmt.Sprintf("👋 %s 欢迎回来，新工单会继续自动分配给您。/ Welcome back, you'll get new tickets again.", atText(userID))
+	}
+	if h.assigner.Enabled() && !h.assigner.InPool(userID) {
+		text += "\n（您不在任何分配池中 / You're not in any assignment pool）"
+	}
+	return text
+}
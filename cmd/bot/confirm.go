@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// confirmCheckInterval 是检查超时自动提交的间隔。
+const confirmCheckInterval = 30 * time.Second
+
+// sendConfirmCard 发送提交确认卡片并进入等待确认状态。
+// 卡片发送失败时退回到直接提交，避免用户卡在确认环节。
+func (h *wrappedMessageHandler) sendConfirmCard(ctx context.Context, chatID, senderID, summary string) error {
+	timeout := h.cfg.Bot.ConfirmTimeoutDuration()
+	msgID, err := h.feishuClient.SendCardMessage(ctx, chatID, feishu.BuildConfirmCard(summary, timeout))
+	if err != nil {
+		log.Printf("[Confirm] Failed to send confirm card, submitting directly: %v", err)
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, "信息收集完毕！/ All info collected!\n\n"+summary+"\n正在为您提交到技术支持团队... / Submitting to the support team...")
+		return h.doEscalation(ctx, chatID, senderID)
+	}
+
+	if err := h.conversationManager.AwaitConfirmation(ctx, chatID, msgID, timeout); err != nil {
+		log.Printf("[Confirm] Failed to save confirmation state: %v", err)
+		return err
+	}
+	log.Printf("[Confirm] Confirm card %s sent to chat %s", msgID, chatID)
+	return nil
+}
+
+// handleConfirmAction 处理确认卡片的提交 / 编辑 / 取消按钮。
+// 飞书要求回调在 3 秒内返回，因此只做状态检查并立即返回新卡片，实际操作在会话锁内异步执行。
+func (h *wrappedMessageHandler) handleConfirmAction(ctx context.Context, action feishu.CardAction) (*feishu.CardActionResult, error) {
+	conv, err := h.conversationManager.GetConversation(ctx, action.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	if conv == nil || !conv.AwaitingConfirmation() {
+		return &feishu.CardActionResult{
+			Toast: "草稿已提交或已失效 / This draft is no longer pending",
+			Card:  feishu.BuildConfirmResultCard("草稿已失效 / Draft no longer pending", feishu.CardTemplateGrey, ""),
+		}, nil
+	}
+	if conv.ConfirmCardID != action.MessageID {
+		return &feishu.CardActionResult{
+			Toast: "请使用最新的确认卡片 / Please use the latest confirmation card",
+			Card:  feishu.BuildConfirmResultCard("卡片已过期 / Card outdated", feishu.CardTemplateGrey, ""),
+		}, nil
+	}
+
+	summary := conv.GetUserSummary()
+	switch action.Action() {
+	case feishu.CardActionSubmit:
+		go h.runLocked(action.ChatID, func(ctx context.Context) { h.submitFromCard(ctx, action) })
+		return &feishu.CardActionResult{
+			Card: feishu.BuildConfirmResultCard("⏳ 正在提交 / Submitting...", feishu.CardTemplateBlue, summary),
+		}, nil
+
+	case feishu.CardActionEdit:
+		go h.runLocked(action.ChatID, func(ctx context.Context) { h.editFromCard(ctx, action) })
+		return &feishu.CardActionResult{
+			Card: feishu.BuildConfirmResultCard("✏️ 编辑中 / Editing", feishu.CardTemplateGrey, summary),
+		}, nil
+
+	case feishu.CardActionCancel:
+		go h.runLocked(action.ChatID, func(ctx context.Context) { h.cancelFromCard(ctx, action) })
+		return &feishu.CardActionResult{
+			Card: feishu.BuildConfirmResultCard("❌ 已取消 / Cancelled", feishu.CardTemplateGrey, summary),
+		}, nil
+	}
+
+	log.Printf("[Confirm] Unknown card action %q", action.Action())
+	return nil, nil
+}
+
+// runLocked 在会话锁内执行 fn（用于消息事件之外的异步入口）。
+func (h *wrappedMessageHandler) runLocked(chatID string, fn func(ctx context.Context)) {
+	unlock := h.eventHandlers.LockChat(chatID)
+	defer unlock()
+	fn(context.Background())
+}
+
+// stillAwaiting 在会话锁内重新确认卡片仍是当前待确认的卡片（期间可能已被关键词提交或更新）。
+func (h *wrappedMessageHandler) stillAwaiting(ctx context.Context, chatID, cardMsgID string) (*models.Conversation, bool) {
+	conv, err := h.conversationManager.GetConversation(ctx, chatID)
+	if err != nil {
+		log.Printf("[Confirm] Failed to get conversation: %v", err)
+		return nil, false
+	}
+	if conv == nil || conv.ConfirmCardID != cardMsgID {
+		return conv, false
+	}
+	return conv, true
+}
+
+// submitFromCard 处理「提交」按钮。
+func (h *wrappedMessageHandler) submitFromCard(ctx context.Context, action feishu.CardAction) {
+	conv, ok := h.stillAwaiting(ctx, action.ChatID, action.MessageID)
+	if !ok {
+		return
+	}
+	if !conv.IsInfoComplete() {
+		h.rejectIncomplete(ctx, conv)
+		return
+	}
+	log.Printf("[Confirm] User %s submitted draft in chat %s", action.OperatorID, action.ChatID)
+	_ = h.doEscalation(ctx, action.ChatID, conv.SenderID)
+}
+
+// editFromCard 处理「编辑」按钮：退出等待确认状态，提示修改方式。
+func (h *wrappedMessageHandler) editFromCard(ctx context.Context, action feishu.CardAction) {
+	if _, ok := h.stillAwaiting(ctx, action.ChatID, action.MessageID); !ok {
+		return
+	}
+	conv, err := h.conversationManager.CancelConfirmation(ctx, action.ChatID)
+	if err != nil {
+		log.Printf("[Confirm] Failed to cancel confirmation: %v", err)
+		return
+	}
+	reply := buildDraftReply("✏️ 请继续编辑 / Continue editing", conv) +
+		"\n\n修改完成后回复「提交」/ Reply \"submit\" when you are ready."
+	_ = h.feishuClient.SendTextMessage(ctx, action.ChatID, reply)
+}
+
+// cancelFromCard 处理「取消」按钮：清除草稿。
+func (h *wrappedMessageHandler) cancelFromCard(ctx context.Context, action feishu.CardAction) {
+	conv, ok := h.stillAwaiting(ctx, action.ChatID, action.MessageID)
+	if !ok {
+		return
+	}
+	h.discardEscalationJob(ctx, conv)
+	if _, err := h.conversationManager.ArchiveConversation(ctx, action.ChatID); err != nil {
+		log.Printf("[Confirm] Failed to clear conversation: %v", err)
+		return
+	}
+	log.Printf("[Confirm] User %s cancelled draft in chat %s", action.OperatorID, action.ChatID)
+	_ = h.feishuClient.SendTextMessage(ctx, action.ChatID, "已取消提交，草稿已清除。如需反馈，请重新描述您的问题。\nSubmission cancelled and your draft was cleared. Describe your issue again whenever you need help.\n\n"+restoreHint)
+}
+
+// autoSubmitDue 定时任务：提交超时未确认的草稿。
+func (h *wrappedMessageHandler) autoSubmitDue(ctx context.Context) {
+	now := time.Now()
+	chatIDs, err := h.conversationManager.ClaimDueConfirmations(ctx, now)
+	if err != nil {
+		log.Printf("[Confirm] Failed to claim due confirmations: %v", err)
+	}
+
+	for _, chatID := range chatIDs {
+		h.runLocked(chatID, func(ctx context.Context) {
+			conv, err := h.conversationManager.GetConversation(ctx, chatID)
+			if err != nil || conv == nil || !conv.AwaitingConfirmation() {
+				return
+			}
+			// 认领后草稿可能已被修改（旧卡片失效，新的确认卡片重新安排了自动提交时间）
+			if conv.ConfirmDeadline.IsZero() || conv.ConfirmDeadline.After(now) {
+				return
+			}
+			if !conv.IsInfoComplete() {
+				h.rejectIncomplete(ctx, conv)
+				return
+			}
+			log.Printf("[Confirm] Auto-submitting draft for chat %s", chatID)
+			_ = h.feishuClient.SendTextMessage(ctx, chatID, "确认超时，已为您自动提交。\nNo response received, your report has been submitted automatically.")
+			_ = h.doEscalation(ctx, chatID, conv.SenderID)
+		})
+	}
+}
+
+// rejectIncomplete 确认卡片提交时信息已不完整（如必填字段被删除）：退出等待确认状态，提示用户补充。
+func (h *wrappedMessageHandler) rejectIncomplete(ctx context.Context, conv *models.Conversation) {
+	log.Printf("[Confirm] Draft for chat %s is incomplete, not submitting", conv.ChatID)
+	updated, err := h.conversationManager.CancelConfirmation(ctx, conv.ChatID)
+	if err != nil {
+		log.Printf("[Confirm] Failed to cancel confirmation: %v", err)
+		return
+	}
+	h.updateConfirmCard(ctx, conv, feishu.BuildConfirmResultCard("信息不完整 / Draft incomplete", feishu.CardTemplateGrey, ""))
+	reply := buildDraftReply("⚠️ 信息不完整，暂未提交 / Draft incomplete, not submitted", updated)
+	_ = h.feishuClient.SendTextMessage(ctx, conv.ChatID, reply)
+}
+
+// expireConfirmation 草稿被修改时退出等待确认状态，并将旧的确认卡片标记为已过期，
+// 返回修改前是否在等待确认（调用方在信息仍完整时发送新的确认卡片）。
+func (h *wrappedMessageHandler) expireConfirmation(ctx context.Context, chatID string) bool {
+	conv, err := h.conversationManager.GetConversation(ctx, chatID)
+	if err != nil || conv == nil || !conv.AwaitingConfirmation() {
+		return false
+	}
+	if _, err := h.conversationManager.CancelConfirmation(ctx, chatID); err != nil {
+		log.Printf("[Confirm] Failed to cancel confirmation: %v", err)
+		return false
+	}
+	h.updateConfirmCard(ctx, conv, feishu.BuildConfirmResultCard("草稿已修改 / Draft changed", feishu.CardTemplateGrey, ""))
+	log.Printf("[Confirm] Confirm card %s expired after draft change in chat %s", conv.ConfirmCardID, chatID)
+	return true
+}
+
+// updateConfirmCard 更新会话的确认卡片状态（会话没有确认卡片时忽略）。
+func (h *wrappedMessageHandler) updateConfirmCard(ctx context.Context, conv *models.Conversation, card map[string]interface{}) {
+	if conv == nil || conv.ConfirmCardID == "" {
+		return
+	}
+	if err := h.feishuClient.UpdateCardMessage(ctx, conv.ConfirmCardID, card); err != nil {
+		log.Printf("[Confirm] Failed to update confirm card %s: %v", conv.ConfirmCardID, err)
+	}
+}
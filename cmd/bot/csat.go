@@ -0,0 +1,200 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// csatReportWeeks 是满意度报告中展示的最近周数。
+const csatReportWeeks = 4
+
+// 满意度评价表单的组件名称。
+const (
+	csatFormRating  = "rating"
+	csatFormComment = "comment"
+)
+
+// sendCSATCard 在工单解决后向用户发送满意度调查卡片（已评价过的工单不再发送）。
+func (h *wrappedMessageHandler) sendCSATCard(ctx context.Context, t *models.Ticket) {
+	if t.CSAT != nil {
+		return
+	}
+	if _, err := h.feishuClient.SendCardMessage(ctx, t.ChatID, buildCSATCard(t)); err != nil {
+		log.Printf("[CSAT] Failed to send survey for ticket %s: %v", t.ID, err)
+	}
+}
+
+// buildCSATCard 构建工单解决后的满意度调查卡片：1~5 分下拉框 + 可选评论。
+func buildCSATCard(t *models.Ticket) map[string]interface{} {
+	var options []interface{}
+	for rating := models.CSATMaxRating; rating >= 1; rating-- {
+		options = append(options, feishu.CardOption(fmt.Sprintf("%s %d", models.CSATStars(rating), rating), strconv.Itoa(rating)))
+	}
+
+	formElements := []interface{}{
+		feishu.CardText("评分 / Rating *"),
+		feishu.CardSelect(csatFormRating, "请选择 / Please select", options, ""),
+		feishu.CardText("评论（可选）/ Comment (optional)"),
+		feishu.CardInput(csatFormComment, "有什么想告诉我们的？/ Anything you'd like to tell us?", ""),
+		feishu.CardFormSubmit("提交评价 / Submit", feishu.CardActionCSATSubmit, map[string]interface{}{feishu.CardTicketKey: t.ID}),
+	}
+
+	elements := []interface{}{
+		feishu.CardText(fmt.Sprintf("您的工单 %s 已解决，请为本次技术支持打分。\nYour ticket %s has been resolved. How satisfied are you with the support?", t.ID, t.ID)),
+		feishu.CardForm("csat_form", formElements),
+	}
+	return feishu.BuildCard("⭐ 满意度调查 / Satisfaction survey", feishu.CardTemplateBlue, elements)
+}
+
+// handleCSATSubmit 处理满意度调查卡片的提交：校验提交人和是否已评价后即时返回感谢卡片，评价在话题锁内异步记录。
+func (h *wrappedMessageHandler) handleCSATSubmit(ctx context.Context, action feishu.CardAction) (*feishu.CardActionResult, error) {
+	ticketID, _ := action.Value[feishu.CardTicketKey].(string)
+	values := action.FormStrings()
+	rating, err := strconv.Atoi(values[csatFormRating])
+	if err != nil || rating < 1 || rating > models.CSATMaxRating {
+		return &feishu.CardActionResult{Toast: "请选择评分 / Please choose a rating"}, nil
+	}
+	comment := strings.TrimSpace(values[csatFormComment])
+
+	// 返回感谢卡片前先确认是用户本人且尚未评价，否则卡片保持不变
+	t, err := h.tickets.Get(ctx, ticketID)
+	if err != nil {
+		log.Printf("[CSAT] Failed to get ticket %s: %v", ticketID, err)
+		return &feishu.CardActionResult{Toast: "评价提交失败，请稍后重试 / Failed to submit, please try again later"}, nil
+	}
+	if t == nil || t.UserID != action.OperatorID {
+		return &feishu.CardActionResult{Toast: "只有提交工单的用户可以评价 / Only the ticket submitter can rate it"}, nil
+	}
+	if t.CSAT != nil {
+		return &feishu.CardActionResult{Toast: "该工单已评价过 / This ticket has already been rated"}, nil
+	}
+
+	go func() {
+		if err := h.recordCSAT(context.Background(), ticketID, action.OperatorID, rating, comment); err != nil {
+			log.Printf("[CSAT] Failed to record rating for ticket %s: %v", ticketID, err)
+		}
+	}()
+
+	summary := models.CSATStars(rating)
+	if comment != "" {
+		summary += "\n" + comment
+	}
+	return &feishu.CardActionResult{
+		Toast: "感谢您的评价 / Thanks for your feedback",
+		Card:  feishu.BuildConfirmResultCard("🙏 感谢您的评价 / Thanks for your feedback · "+ticketID, feishu.CardTemplateGreen, summary),
+	}, nil
+}
+
+// recordCSAT 记录用户对工单的评价（每个工单只记录一次），计入周 / 处理人汇总并发到工单话题。
+func (h *wrappedMessageHandler) recordCSAT(ctx context.Context, ticketID, operatorID string, rating int, comment string) error {
+	t, err := h.tickets.Get(ctx, ticketID)
+	if err != nil {
+		return err
+	}
+	if t == nil || t.UserID != operatorID {
+		return fmt.Errorf("ticket %s not found for user %s", ticketID, operatorID)
+	}
+
+	var recordErr error
+	h.runLocked(t.RootMsgID, func(ctx context.Context) {
+		// 锁内重新读取，避免重复提交或与工单命令同时修改
+		t, recordErr = h.tickets.Get(ctx, ticketID)
+		if recordErr != nil || t == nil || t.CSAT != nil {
+			return
+		}
+
+		now := time.Now()
+		t.CSAT = &models.CSATResponse{Rating: rating, Comment: comment, SubmittedAt: now}
+		if recordErr = h.tickets.Save(ctx, t); recordErr != nil {
+			return
+		}
+		year, week := now.In(h.cfg.Bot.Location()).ISOWeek()
+		if err := h.tickets.RecordCSAT(ctx, fmt.Sprintf("%d-W%02d", year, week), t.Engineer(), rating); err != nil {
+			log.Printf("[CSAT] %v", err)
+		}
+		log.Printf("[CSAT] Ticket %s rated %d by %s", t.ID, rating, operatorID)
+
+		if t.RootMsgID == "" {
+			return
+		}
+		text := fmt.Sprintf("⭐ 用户评价 / CSAT: %s（%d/%d）", models.CSATStars(rating), rating, models.CSATMaxRating)
+		if comment != "" {
+			text += "\n" + comment
+		}
+		if err := h.feishuClient.ReplyTextInThread(ctx, t.RootMsgID, text); err != nil {
+			log.Printf("[CSAT] Failed to post rating to ticket %s: %v", t.ID, err)
+		}
+	})
+	return recordErr
+}
+
+// HandleReaction 处理表情回复：技术支持在工单根消息上添加 resolve_reactions 中的表情即标记已解决。
+func (h *wrappedMessageHandler) HandleReaction(ctx context.Context, messageID, operatorID, emojiType string) error {
+	if !h.cfg.Bot.IsResolveReaction(emojiType) {
+		return nil
+	}
+
+	var cmdErr error
+	h.runLocked(messageID, func(ctx context.Context) {
+		t, err := h.tickets.GetByRootMessage(ctx, messageID)
+		if err != nil {
+			cmdErr = err
+			return
+		}
+		// 非工单根消息、用户本人或已结束的工单忽略，不在话题内回复
+		if t == nil || operatorID == t.UserID || t.IsClosed() {
+			return
+		}
+		h.recordFirstResponse(ctx, t)
+		cmdErr = h.handleAgentCommand(ctx, feishu.ThreadMessage{
+			ChatID:      t.GroupID,
+			RootID:      messageID,
+			MessageID:   messageID,
+			SenderID:    operatorID,
+			Content:     "resolve",
+			MsgType:     "text",
+			MentionsBot: true,
+		}, t)
+	})
+	return cmdErr
+}
+
+// buildCSATReport 构建满意度汇总：最近几周和各处理人的平均分（话题内 csat 命令）。
+func (h *wrappedMessageHandler) buildCSATReport(ctx context.Context) string {
+	weeks, err := h.tickets.CSATByWeek(ctx)
+	if err != nil {
+		log.Printf("[CSAT] %v", err)
+		return "抱歉，获取满意度统计失败。/ Failed to load CSAT stats."
+	}
+	engineers, err := h.tickets.CSATByEngineer(ctx)
+	if err != nil {
+		log.Printf("[CSAT] %v", err)
+		return "抱歉，获取满意度统计失败。/ Failed to load CSAT stats."
+	}
+	if len(weeks) == 0 {
+		return "暂无满意度评价。/ No CSAT responses yet."
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⭐ 满意度统计 / CSAT\n\n按周 / By week:")
+	for i, w := range weeks {
+		if i >= csatReportWeeks {
+			break
+		}
+		sb.WriteString(fmt.Sprintf("\n  %s: %.2f（%d 条）", w.Key, w.Average(), w.Count))
+	}
+	if len(engineers) > 0 {
+		sb.WriteString("\n\n按处理人 / By engineer:")
+		for _, e := range engineers {
+			sb.WriteString(fmt.Sprintf("\n  %s: %.2f（%d 条）", atText(e.Key), e.Average(), e.Count))
+		}
+	}
+	return sb.String()
+}
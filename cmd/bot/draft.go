@@ -0,0 +1,323 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/even/feishu-bot/internal/conversation"
+	"github.com/even/feishu-bot/internal/handler"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// draftCommandKind 表示草稿编辑命令的类型。
+type draftCommandKind int
+
+const (
+	draftShow       draftCommandKind = iota + 1 // 查看草稿
+	draftSet                                    // 修改字段
+	draftDelete                                 // 删除字段
+	draftRemoveFile                             // 删除最后一个附件
+	draftRestore                                // 恢复最近归档的草稿
+	draftRoute                                  // 预览草稿提交后的转人工路由
+)
+
+// restoreHint 提示用户可以恢复被清除的草稿。
+const restoreHint = "草稿已归档，发送「恢复草稿」可找回。/ Your draft was archived, send \"restore\" to bring it back."
+
+// draftCommand 是解析后的草稿编辑命令。
+type draftCommand struct {
+	kind  draftCommandKind
+	field models.FieldDef
+	value string
+}
+
+var (
+	draftShowCommands = []string{
+		"查看草稿", "我的草稿", "查看信息", "show draft", "show my draft", "view draft", "my draft",
+	}
+	draftRemoveFileCommands = []string{
+		"删除最后附件", "删除最后一个附件", "删除上一个附件", "remove last attachment", "delete last attachment",
+	}
+	draftRestoreCommands = []string{
+		"恢复草稿", "恢复", "撤销清除", "restore", "restore draft", "undo clear",
+	}
+	draftRouteCommands = []string{
+		"路由预览", "预览路由", "route preview", "preview route", "dry run",
+	}
+	draftSetPrefixes    = []string{"修改", "设置", "更改", "set ", "update "}
+	draftDeletePrefixes = []string{"删除", "delete ", "remove "}
+	// draftFieldBoundaries 是可以紧跟在字段名之后的分隔符。
+	draftFieldBoundaries = []string{":", "：", "=", "为", "成", "是"}
+	// draftValueSeparators 是字段名与值之间允许的分隔符（会从值开头去除）。
+	draftValueSeparators = append([]string{"to "}, draftFieldBoundaries...)
+)
+
+// parseDraftCommand 解析草稿编辑命令（支持中英文）：
+//
+//	查看草稿 / show draft
+//	修改 <字段> <值> / set <field> <value>
+//	删除 <字段> / delete <field>
+//	删除最后附件 / remove last attachment
+//	恢复草稿 / restore
+//	路由预览 / route preview
+//
+// 只有识别出字段名时才视为命令，避免把 "删除照片后闪退" 这类问题描述当作命令。
+func parseDraftCommand(content string) (draftCommand, bool) {
+	text := strings.TrimSpace(content)
+	lower := strings.ToLower(text)
+
+	for _, c := range draftShowCommands {
+		if lower == c {
+			return draftCommand{kind: draftShow}, true
+		}
+	}
+	for _, c := range draftRemoveFileCommands {
+		if lower == c {
+			return draftCommand{kind: draftRemoveFile}, true
+		}
+	}
+	for _, c := range draftRestoreCommands {
+		if lower == c {
+			return draftCommand{kind: draftRestore}, true
+		}
+	}
+	for _, c := range draftRouteCommands {
+		if lower == c {
+			return draftCommand{kind: draftRoute}, true
+		}
+	}
+
+	for _, p := range draftSetPrefixes {
+		if !strings.HasPrefix(lower, p) {
+			continue
+		}
+		rest := strings.TrimSpace(text[len(p):])
+		field, value, ok := splitFieldValue(rest)
+		if ok && value != "" {
+			return draftCommand{kind: draftSet, field: field, value: value}, true
+		}
+	}
+
+	for _, p := range draftDeletePrefixes {
+		if !strings.HasPrefix(lower, p) {
+			continue
+		}
+		rest := strings.TrimSpace(text[len(p):])
+		field, value, ok := splitFieldValue(rest)
+		if ok && value == "" {
+			return draftCommand{kind: draftDelete, field: field}, true
+		}
+	}
+
+	return draftCommand{}, false
+}
+
+// splitFieldValue 从 "<字段> <值>" 中识别字段（最长别名前缀匹配）并拆出值。
+// 字段名后必须是结尾、空白或分隔符，否则不视为字段名。
+func splitFieldValue(text string) (models.FieldDef, string, bool) {
+	lower := strings.ToLower(text)
+	var best models.FieldDef
+	bestLen := 0
+	for _, f := range models.AllFields() {
+		for _, alias := range f.FieldAliases() {
+			a := strings.ToLower(alias)
+			if a == "" || len(a) <= bestLen || !strings.HasPrefix(lower, a) {
+				continue
+			}
+			if !isFieldNameEnd(text[len(a):]) {
+				continue
+			}
+			best, bestLen = f, len(a)
+		}
+	}
+	if bestLen == 0 {
+		return models.FieldDef{}, "", false
+	}
+
+	value := strings.TrimSpace(text[bestLen:])
+	for _, sep := range draftValueSeparators {
+		if strings.HasPrefix(strings.ToLower(value), sep) {
+			value = strings.TrimSpace(value[len(sep):])
+			break
+		}
+	}
+	return best, value, true
+}
+
+// isFieldNameEnd 判断字段名之后的内容是否构成合法的边界（结尾、空白或分隔符）。
+func isFieldNameEnd(rest string) bool {
+	if rest == "" {
+		return true
+	}
+	if r := []rune(rest)[0]; unicode.IsSpace(r) {
+		return true
+	}
+	for _, sep := range draftFieldBoundaries {
+		if strings.HasPrefix(rest, sep) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleDraftCommand 执行草稿编辑命令，回复更新后的草稿摘要。
+func (h *wrappedMessageHandler) handleDraftCommand(ctx context.Context, chatID, senderID string, cmd draftCommand) error {
+	var header string
+
+	switch cmd.kind {
+	case draftShow:
+		conv, err := h.conversationManager.GetConversation(ctx, chatID)
+		if err != nil {
+			return h.replyDraftError(ctx, chatID, err)
+		}
+		return h.feishuClient.SendTextMessage(ctx, chatID, buildDraftReply("📋 当前草稿 / Current draft", conv))
+
+	case draftSet:
+		conv, err := h.conversationManager.SetField(ctx, chatID, senderID, cmd.field, cmd.value)
+		var verr *conversation.ValidationError
+		switch {
+		case errors.As(err, &verr):
+			msg := fmt.Sprintf("⚠️ %s:「%s」%s\n请重新输入 / Please try again.", cmd.field.Name, cmd.value, verr.Reason)
+			return h.feishuClient.SendTextMessage(ctx, chatID, msg)
+		case errors.Is(err, conversation.ErrAmbiguousTime):
+			header = fmt.Sprintf("✅ 已更新 / Updated: %s\n❓ 时间不够具体，请补充日期和大概几点 / Please include the date and approximate time", cmd.field.Name)
+		case err != nil:
+			return h.replyDraftError(ctx, chatID, err)
+		default:
+			header = fmt.Sprintf("✅ 已更新 / Updated: %s", cmd.field.Name)
+		}
+		log.Printf("[Handler] Draft field %s set by user in chat %s", cmd.field.Key, chatID)
+		return h.replyDraftChange(ctx, chatID, header, conv)
+
+	case draftDelete:
+		conv, err := h.conversationManager.DeleteField(ctx, chatID, cmd.field)
+		if err != nil {
+			return h.replyDraftError(ctx, chatID, err)
+		}
+		header = fmt.Sprintf("🗑 已删除 / Deleted: %s", cmd.field.Name)
+		return h.replyDraftChange(ctx, chatID, header, conv)
+
+	case draftRemoveFile:
+		conv, removed, err := h.conversationManager.RemoveLastFile(ctx, chatID)
+		if errors.Is(err, conversation.ErrNoFiles) {
+			return h.feishuClient.SendTextMessage(ctx, chatID, "草稿中没有附件。\nThere are no attachments in your draft.")
+		}
+		if err != nil {
+			return h.replyDraftError(ctx, chatID, err)
+		}
+		header = fmt.Sprintf("🗑 已删除附件 / Removed attachment: %s", removed.FileName)
+		return h.replyDraftChange(ctx, chatID, header, conv)
+
+	case draftRestore:
+		return h.restoreDraft(ctx, chatID)
+
+	case draftRoute:
+		conv, err := h.conversationManager.GetConversation(ctx, chatID)
+		if err != nil {
+			return h.replyDraftError(ctx, chatID, err)
+		}
+		if conv == nil || !conv.HasDraft() {
+			return h.feishuClient.SendTextMessage(ctx, chatID, "当前没有草稿，无法预览路由。\nThere is no draft to preview.")
+		}
+		return h.feishuClient.SendTextMessage(ctx, chatID, h.buildRoutePreview(conv))
+	}
+
+	return nil
+}
+
+// replyDraftChange 回复修改后的草稿：旧的确认卡片失效，修改前在等待确认且信息仍完整时重新发送确认卡片。
+func (h *wrappedMessageHandler) replyDraftChange(ctx context.Context, chatID, header string, conv *models.Conversation) error {
+	awaiting := h.expireConfirmation(ctx, chatID)
+	if awaiting && conv != nil && conv.Mode != models.ModeSuggestion && conv.IsInfoComplete() {
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, header)
+		return h.sendConfirmCard(ctx, chatID, conv.SenderID, conv.GetUserSummary())
+	}
+	return h.feishuClient.SendTextMessage(ctx, chatID, buildDraftReply(header, conv))
+}
+
+// restoreDraft 恢复最近被清除或过期的草稿；信息完整时重新发送确认卡片。
+func (h *wrappedMessageHandler) restoreDraft(ctx context.Context, chatID string) error {
+	conv, err := h.conversationManager.RestoreConversation(ctx, chatID)
+	switch {
+	case errors.Is(err, conversation.ErrNoArchive):
+		return h.feishuClient.SendTextMessage(ctx, chatID, "没有可恢复的草稿。\nThere is no draft to restore.")
+	case errors.Is(err, conversation.ErrNewerDraft):
+		return h.feishuClient.SendTextMessage(ctx, chatID, buildDraftReply("已有新的草稿，无法恢复之前的草稿。/ A newer draft exists, the previous one cannot be restored.", conv))
+	case err != nil:
+		return h.replyDraftError(ctx, chatID, err)
+	}
+
+	if conv.Mode != models.ModeSuggestion && conv.IsInfoComplete() {
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, "♻️ 已恢复草稿 / Draft restored")
+		return h.sendConfirmCard(ctx, chatID, conv.SenderID, conv.GetUserSummary())
+	}
+	return h.feishuClient.SendTextMessage(ctx, chatID, buildDraftReply("♻️ 已恢复草稿 / Draft restored", conv))
+}
+
+// buildRoutePreview 构建路由预览（dry run）：草稿现在提交时的目标群组、话题标题、额外 @ 的人和命中的规则。
+func (h *wrappedMessageHandler) buildRoutePreview(conv *models.Conversation) string {
+	target := h.escalationHandler.Route(conv)
+	route := target.Route
+	if route == "" {
+		route = "默认 / default"
+	}
+
+	var sb strings.Builder
+	sb.WriteString("🧭 路由预览 / Route preview\n")
+	sb.WriteString(fmt.Sprintf("路由 / Route: %s\n", route))
+	sb.WriteString(fmt.Sprintf("群组 / Group: %s\n", target.GroupID))
+	sb.WriteString(fmt.Sprintf("标题 / Title: %s\n", handler.TitleFor(target.Title, conv.Mode)))
+	if len(target.Mentions) > 0 {
+		mentions := make([]string, 0, len(target.Mentions))
+		for _, id := range target.Mentions {
+			mentions = append(mentions, atText(id))
+		}
+		sb.WriteString(fmt.Sprintf("@: %s\n", strings.Join(mentions, " ")))
+	}
+	if matched := h.escalationHandler.RouteMatches(conv); len(matched) > 1 {
+		sb.WriteString(fmt.Sprintf("命中的规则 / Matched: %s（第一条生效 / first wins）\n", strings.Join(matched, "、")))
+	}
+	sb.WriteString("\n仅预览，不会提交。/ Preview only, nothing was submitted.")
+	return sb.String()
+}
+
+// replyDraftError 记录草稿命令失败并提示用户。
+func (h *wrappedMessageHandler) replyDraftError(ctx context.Context, chatID string, err error) error {
+	log.Printf("[Handler] Draft command failed: %v", err)
+	_ = h.feishuClient.SendTextMessage(ctx, chatID, "抱歉，处理草稿时出错了，请稍后重试。\nSorry, failed to update your draft. Please try again later.")
+	return err
+}
+
+// buildDraftReply 构建草稿摘要回复：标题 + 已填写字段 + 缺失字段 + 命令提示。
+func buildDraftReply(header string, conv *models.Conversation) string {
+	var sb strings.Builder
+	sb.WriteString(header)
+	sb.WriteString("\n\n")
+
+	summary := ""
+	if conv != nil {
+		summary = conv.GetUserSummary()
+	}
+	if summary == "" {
+		sb.WriteString("草稿为空 / Your draft is empty.\n")
+	} else {
+		sb.WriteString(summary)
+	}
+
+	if conv != nil {
+		if missing := conv.GetMissingFields(); len(missing) > 0 {
+			sb.WriteString("\n还需要 / Still need:\n")
+			for _, name := range missing {
+				sb.WriteString(fmt.Sprintf("  - %s\n", name))
+			}
+		}
+	}
+
+	sb.WriteString("\n修改：「修改 字段 值」/ \"set <field> <value>\"\n")
+	sb.WriteString("删除：「删除 字段」/ \"delete <field>\"、「删除最后附件」/ \"remove last attachment\"")
+	return sb.String()
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+func TestParseDraftCommand(t *testing.T) {
+	models.SetFieldSchema([]models.FieldDef{
+		{Key: "issue", Name: "问题描述 / Issue Description", ShortName: "问题描述", Aliases: []string{"问题", "描述"}},
+		{Key: "occur_time", Name: "发生时间 / Time of Occurrence", ShortName: "发生时间", Aliases: []string{"时间", "time"}},
+		{Key: "app_version", Name: "应用版本 / App Version", ShortName: "应用版本", Aliases: []string{"App", "应用", "app version"}},
+		{Key: "glasses_version", Name: "眼镜版本 / Glasses Firmware", ShortName: "眼镜版本", Aliases: []string{"眼镜", "眼镜固件", "glasses", "glasses version"}},
+		{Key: "ring_version", Name: "戒指版本 / Ring Firmware", ShortName: "戒指版本", Aliases: []string{"戒指", "戒指固件", "ring", "ring version"}},
+	})
+	defer models.SetFieldSchema(nil)
+
+	tests := []struct {
+		text  string
+		ok    bool
+		kind  draftCommandKind
+		field string
+		value string
+	}{
+		{text: "查看草稿", ok: true, kind: draftShow},
+		{text: " Show Draft ", ok: true, kind: draftShow},
+		{text: "删除最后附件", ok: true, kind: draftRemoveFile},
+		{text: "remove last attachment", ok: true, kind: draftRemoveFile},
+		{text: "恢复草稿", ok: true, kind: draftRestore},
+		{text: "dry run", ok: true, kind: draftRoute},
+
+		{text: "修改 应用版本 2.1.0", ok: true, kind: draftSet, field: "app_version", value: "2.1.0"},
+		{text: "修改眼镜版本为1.2", ok: true, kind: draftSet, field: "glasses_version", value: "1.2"},
+		{text: "修改 眼镜 1.2", ok: true, kind: draftSet, field: "glasses_version", value: "1.2"},
+		{text: "设置时间：昨天下午3点", ok: true, kind: draftSet, field: "occur_time", value: "昨天下午3点"},
+		{text: "set app version to 2.1", ok: true, kind: draftSet, field: "app_version", value: "2.1"},
+		{text: "Set ring 2.0", ok: true, kind: draftSet, field: "ring_version", value: "2.0"},
+		{text: "update glasses version: 1.3.0", ok: true, kind: draftSet, field: "glasses_version", value: "1.3.0"},
+
+		{text: "删除 戒指", ok: true, kind: draftDelete, field: "ring_version"},
+		{text: "删除戒指固件", ok: true, kind: draftDelete, field: "ring_version"},
+		{text: "delete app version", ok: true, kind: draftDelete, field: "app_version"},
+
+		{text: "删除照片后闪退"},
+		{text: "修改头像后 App 闪退"},
+		{text: "删除 戒指 固件后连不上"},
+		{text: "修改 应用版本"},
+		{text: "set up the glasses failed"},
+		{text: "update 之后眼镜连不上"},
+		{text: "草稿"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			cmd, ok := parseDraftCommand(tt.text)
+			if ok != tt.ok {
+				t.Fatalf("parseDraftCommand(%q) ok = %v, want %v (cmd %+v)", tt.text, ok, tt.ok, cmd)
+			}
+			if !ok {
+				return
+			}
+			if cmd.kind != tt.kind || cmd.field.Key != tt.field || cmd.value != tt.value {
+				t.Errorf("parseDraftCommand(%q) = {kind %d, field %q, value %q}, want {kind %d, field %q, value %q}",
+					tt.text, cmd.kind, cmd.field.Key, cmd.value, tt.kind, tt.field, tt.value)
+			}
+		})
+	}
+}
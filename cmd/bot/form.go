@@ -0,0 +1,146 @@
+package main
+
+import (
+	"context"
+	"log"
+	"strings"
+	"unicode"
+
+	"github.com/even/feishu-bot/internal/config"
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// formCommands 是打开表单卡片的命令（精确匹配，忽略大小写）。
+var formCommands = []string{"填写表单", "打开表单", "表单", "form", "open form", "fill form"}
+
+// isFormCommand 判断消息是否为打开表单卡片的命令。
+func isFormCommand(content string) bool {
+	lower := strings.ToLower(strings.TrimSpace(content))
+	for _, c := range formCommands {
+		if lower == c {
+			return true
+		}
+	}
+	return false
+}
+
+// sendFormCard 发送信息收集表单卡片（预填已收集的信息）。
+func (h *wrappedMessageHandler) sendFormCard(ctx context.Context, chatID string) error {
+	conv, err := h.conversationManager.GetConversation(ctx, chatID)
+	if err != nil {
+		log.Printf("[Form] Failed to get conversation: %v", err)
+		return err
+	}
+
+	msgID, err := h.feishuClient.SendCardMessage(ctx, chatID, buildFormCard(conv))
+	if err != nil {
+		log.Printf("[Form] Failed to send form card: %v", err)
+		return err
+	}
+	log.Printf("[Form] Form card %s sent to chat %s", msgID, chatID)
+	return h.conversationManager.MarkFormCardSent(ctx, chatID, msgID)
+}
+
+// maybeSendFormCard 在 auto 模式下，会话信息未完整且尚未发送过表单时主动发送表单卡片（补充信息会话除外）。
+func (h *wrappedMessageHandler) maybeSendFormCard(ctx context.Context, chatID string) {
+	if h.cfg.Bot.FormCard != config.FormCardAuto {
+		return
+	}
+	conv, err := h.conversationManager.GetConversation(ctx, chatID)
+	if err != nil || conv == nil || conv.Mode != models.ModeIssue || conv.FormCardID != "" || conv.Supplement != nil ||
+		conv.AwaitingConfirmation() || conv.IsInfoComplete() {
+		return
+	}
+	_ = h.sendFormCard(ctx, chatID)
+}
+
+// handleFormSubmit 处理表单卡片提交：在会话锁内异步合并表单值，再按正常流程检查完整性并回复。
+func (h *wrappedMessageHandler) handleFormSubmit(ctx context.Context, action feishu.CardAction) (*feishu.CardActionResult, error) {
+	values := action.FormStrings()
+	go h.runLocked(action.ChatID, func(ctx context.Context) {
+		response, err := h.conversationManager.ApplyFormValues(ctx, action.ChatID, action.OperatorID, values)
+		if err != nil {
+			log.Printf("[Form] ApplyFormValues failed: %v", err)
+			_ = h.feishuClient.SendTextMessage(ctx, action.ChatID, "抱歉，处理表单时出错了，请稍后重试。\nSorry, failed to process the form. Please try again later.")
+			return
+		}
+		_ = h.deliverResponse(ctx, action.ChatID, action.OperatorID, response)
+	})
+
+	return &feishu.CardActionResult{
+		Toast: "表单已提交 / Form submitted",
+		Card:  feishu.BuildConfirmResultCard("📝 表单已提交 / Form submitted", feishu.CardTemplateGrey, "如需修改，请发送「填写表单」重新打开。\nSend \"form\" to open it again."),
+	}, nil
+}
+
+// buildFormCard 根据字段配置构建信息收集表单卡片：枚举字段使用下拉框，其余字段使用输入框，
+// 并预填会话中已收集的值。表单提交后回调的 form_value 为字段 key → 值。
+func buildFormCard(conv *models.Conversation) map[string]interface{} {
+	var formElements []interface{}
+	for _, field := range models.AllFields() {
+		formElements = append(formElements, feishu.CardText(formFieldLabel(field)))
+		if field.Validate == models.ValidateEnum && len(field.Enum) > 0 {
+			formElements = append(formElements, formSelect(field, conv))
+		} else {
+			formElements = append(formElements, formInput(field, conv))
+		}
+	}
+	formElements = append(formElements, feishu.CardFormSubmit("提交表单 / Submit form", feishu.CardActionFormSubmit, nil))
+
+	elements := []interface{}{
+		feishu.CardNote("一次填写全部信息，留空的项不会修改已有内容 / Fill in everything at once; empty fields keep their current value"),
+		feishu.CardForm("info_form", formElements),
+	}
+	return feishu.BuildCard("📝 填写问题信息 / Report form", feishu.CardTemplateBlue, elements)
+}
+
+// formFieldLabel 返回表单中字段的标题（标注条件必填 / 可选）。
+func formFieldLabel(field models.FieldDef) string {
+	switch {
+	case !field.IsRequired():
+		return field.Name + "（可选 / optional）"
+	case field.IsConditional():
+		return field.Name + "（如适用 / if applicable）"
+	default:
+		return field.Name + " *"
+	}
+}
+
+// formInput 构建输入框，预填已收集的原文值。
+func formInput(field models.FieldDef, conv *models.Conversation) map[string]interface{} {
+	placeholder := "请输入 / Please enter"
+	if len(field.Examples) > 0 {
+		placeholder = "例如 / e.g. " + field.Examples[0]
+	}
+	value := ""
+	if conv != nil {
+		value, _ = conv.GetCollectedInfo(field.Key)
+	}
+	return feishu.CardInput(field.Key, placeholder, value)
+}
+
+// formSelect 构建下拉框，预填已标准化的枚举值。
+func formSelect(field models.FieldDef, conv *models.Conversation) map[string]interface{} {
+	var options []interface{}
+	for _, opt := range field.Enum {
+		options = append(options, feishu.CardOption(enumOptionLabel(opt), opt.Value))
+	}
+	initial := ""
+	if conv != nil {
+		initial = conv.NormalizedInfo[field.Key]
+	}
+	return feishu.CardSelect(field.Key, "请选择 / Please select", options, initial)
+}
+
+// enumOptionLabel 返回枚举选项的显示文本：有中文同义词时显示为「中文 / value」。
+func enumOptionLabel(opt models.EnumOption) string {
+	for _, syn := range opt.Synonyms {
+		for _, r := range syn {
+			if r > unicode.MaxASCII {
+				return syn + " / " + opt.Value
+			}
+		}
+	}
+	return opt.Value
+}
@@ -9,12 +9,21 @@ import (
 	"os/signal"
 	"strings"
 	"syscall"
+	"time"
+	_ "time/tzdata" // 内置时区数据（alpine 镜像不含 tzdata）
 
+	"github.com/even/feishu-bot/internal/assign"
 	"github.com/even/feishu-bot/internal/config"
 	"github.com/even/feishu-bot/internal/conversation"
 	"github.com/even/feishu-bot/internal/feishu"
 	"github.com/even/feishu-bot/internal/handler"
 	"github.com/even/feishu-bot/internal/llm"
+	"github.com/even/feishu-bot/internal/oncall"
+	"github.com/even/feishu-bot/internal/scheduler"
+	"github.com/even/feishu-bot/internal/severity"
+	"github.com/even/feishu-bot/internal/ticket"
+	"github.com/even/feishu-bot/internal/workhours"
+	"github.com/even/feishu-bot/pkg/models"
 )
 
 var (
@@ -31,17 +40,27 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// 加载字段定义
+	models.SetFieldSchema(cfg.Fields)
+
 	// 初始化 Redis 存储
 	store, err := conversation.NewStore(
 		cfg.Redis.Addr,
 		cfg.Redis.Password,
 		cfg.Redis.DB,
-		cfg.Redis.Expiration,
+		cfg.ConversationTTL(),
 	)
 	if err != nil {
 		log.Fatalf("Failed to initialize Redis store: %v", err)
 	}
 	defer store.Close()
+	store.SetArchiveRetention(time.Duration(cfg.Redis.ArchiveRetention) * time.Second)
+	// session_timeout 为 0 时草稿随 redis.expiration 过期，此时不提醒，只归档
+	var remindBefore time.Duration
+	if cfg.Bot.SessionTimeout > 0 {
+		remindBefore = cfg.Bot.SessionReminderDuration()
+	}
+	store.SetSessionTimeout(cfg.DraftExpiryDuration(), remindBefore)
 
 	promptMgr, err := conversation.NewPromptManager("configs/prompts.yaml")
 	if err != nil {
@@ -53,24 +72,69 @@ func main() {
 		cfg.LLM.BaseURL,
 		cfg.LLM.APIKey,
 		cfg.LLM.Model,
+		cfg.Fields,
 	)
 	if err != nil {
 		log.Printf("Warning: failed to initialize LLM client, running without LLM: %v", err)
 		llmClient = nil
 	}
 
-	convMgr := conversation.NewManager(store, llmClient, promptMgr)
+	convMgr := conversation.NewManager(store, llmClient, promptMgr, conversation.ManagerOptions{
+		Location:            cfg.Bot.Location(),
+		ConfidenceThreshold: cfg.LLM.ConfidenceThreshold,
+	})
+
+	// 工单存储（与会话存储共用 Redis 连接）
+	tickets := ticket.NewStore(store.Client())
+
+	// 值班排班（配置了排班文件时修改后自动生效）
+	schedule, err := oncall.New(cfg.OnCall, cfg.Bot.Location())
+	if err != nil {
+		log.Fatalf("Failed to load on-call schedule: %v", err)
+	}
+
+	// 工作时间日历（未配置时视为全天工作）
+	hours, err := workhours.New(cfg.BusinessHours, cfg.Bot.Location())
+	if err != nil {
+		log.Fatalf("Failed to load business hours: %v", err)
+	}
+
+	// 自动分配（未配置分配池时不分配）
+	assigner, err := assign.New(cfg.Assignment, tickets)
+	if err != nil {
+		log.Fatalf("Failed to load assignment config: %v", err)
+	}
+
+	// 严重程度分级（未配置时不分级）
+	var classifier *severity.Classifier
+	if cfg.Severity.Enabled() {
+		var severityLLM llm.Client
+		if llmClient != nil {
+			severityLLM = llmClient
+		}
+		classifier = severity.New(cfg.Severity, severityLLM)
+	}
 
 	// 初始化转人工处理器
 	escalationHandler := handler.NewEscalationHandler(
 		nil, // 稍后设置
 		cfg.Feishu.EscalationGroupID,
+		cfg.Routes,
+		schedule,
+		cfg.Severity.Critical,
+		hours,
+		assigner,
+		tickets,
 	)
 
 	// 创建包装器连接转人工处理器和事件处理器
 	wrappedHandler := &wrappedMessageHandler{
 		conversationManager: convMgr,
 		escalationHandler:   escalationHandler,
+		tickets:             tickets,
+		oncall:              schedule,
+		severity:            classifier,
+		assigner:            assigner,
 		feishuClient:        nil, // 稍后设置
 		cfg:                 cfg,
 	}
@@ -87,6 +151,7 @@ func main() {
 
 	// 更新处理器的飞书客户端
 	wrappedHandler.feishuClient = feishuClient
+	wrappedHandler.eventHandlers = feishuHandlers
 	escalationHandler.SetFeishuClient(feishuClient)
 	feishuHandlers.SetFeishuClient(feishuClient)
 
@@ -94,6 +159,23 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	// 启动后台定时任务
+	sched := scheduler.New()
+	if cfg.Bot.ConfirmTimeout > 0 {
+		sched.Every("confirm-auto-submit", confirmCheckInterval, wrappedHandler.autoSubmitDue)
+	}
+	if cfg.DraftExpiryDuration() > 0 {
+		sched.Every("session-expiry", sessionCheckInterval, wrappedHandler.expireIdleSessions)
+	}
+	if cfg.Bot.SessionTimeout > 0 && cfg.Bot.SessionReminder > 0 {
+		sched.Every("session-reminder", sessionCheckInterval, wrappedHandler.remindIdleSessions)
+	}
+	sched.Every("escalation-outbox", outboxCheckInterval, wrappedHandler.retryEscalations)
+	if cfg.SLA.Enabled() {
+		sched.Every("ticket-sla", slaCheckInterval, wrappedHandler.checkSLA)
+	}
+	sched.Start(ctx)
+
 	// 处理关闭信号
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
@@ -126,22 +208,65 @@ func main() {
 type wrappedMessageHandler struct {
 	conversationManager *conversation.Manager
 	escalationHandler   *handler.EscalationHandler
+	tickets             *ticket.Store
+	oncall              *oncall.Schedule
+	severity            *severity.Classifier // 严重程度分级（未配置时为 nil）
+	assigner            *assign.Assigner
 	feishuClient        *feishu.Client
+	eventHandlers       *feishu.EventHandlers // 提供会话锁（卡片回调、定时任务使用）
 	cfg                 *config.Config
 }
 
 // HandleMessage 处理用户消息。
 func (h *wrappedMessageHandler) HandleMessage(ctx context.Context, chatID, senderID, messageID, content, msgType, fileKey string) error {
+	// 草稿编辑命令优先处理（关键词是子串匹配，避免 "修改 问题 ..." 的值误触发清除/转人工）
+	if msgType == "text" {
+		if cmd, ok := parseDraftCommand(content); ok {
+			return h.handleDraftCommand(ctx, chatID, senderID, cmd)
+		}
+	}
+
+	// 查询 / 重新打开工单
+	if msgType == "text" {
+		if isMyTicketsCommand(content) {
+			return h.handleMyTickets(ctx, chatID, senderID)
+		}
+		if query, ok := parseTicketQuery(content); ok {
+			return h.handleTicketQuery(ctx, chatID, senderID, query)
+		}
+		if id, note, ok := parseReopenCommand(content); ok {
+			return h.handleUserReopen(ctx, chatID, senderID, id, note)
+		}
+	}
+
+	// 打开表单卡片
+	if msgType == "text" && h.cfg.Bot.FormCard != config.FormCardOff && isFormCommand(content) {
+		return h.sendFormCard(ctx, chatID)
+	}
+
 	// 检查是否需要清除上下文
 	if msgType == "text" && h.cfg.IsClearContextKeyword(content) {
 		return h.handleClearContext(ctx, chatID)
 	}
 
+	// 开始新问题：结束提交后 / 技术支持回复后的转发窗口
+	if msgType == "text" && isNewIssueCommand(content) {
+		return h.handleNewIssue(ctx, chatID)
+	}
+
+	// 提交后或技术支持回复后的转发窗口内，用户消息回复到工单话题
+	if relayed, err := h.relayFollowUp(ctx, chatID, messageID, content, fileKey); relayed {
+		return err
+	}
+
 	// 检查是否需要转人工（用户主动触发）
 	if msgType == "text" && h.cfg.IsEscalationKeyword(content) {
 		return h.HandleEscalation(ctx, chatID, senderID, content)
 	}
 
+	// 草稿将被修改，旧的确认卡片失效（信息仍完整时 ProcessMessage 会要求发送新的确认卡片）
+	h.expireConfirmation(ctx, chatID)
+
 	// 处理消息并获取回复
 	response, err := h.conversationManager.ProcessMessage(ctx, chatID, senderID, "", content, msgType, fileKey, messageID)
 	if err != nil {
@@ -150,7 +275,27 @@ func (h *wrappedMessageHandler) HandleMessage(ctx context.Context, chatID, sende
 		return err
 	}
 
-	// 检查是否需要自动转人工（信息收集完毕）
+	if err := h.deliverResponse(ctx, chatID, senderID, response); err != nil {
+		return err
+	}
+	h.maybeSendFormCard(ctx, chatID)
+	return nil
+}
+
+// deliverResponse 按 ProcessMessage 返回值的约定发送回复：确认卡片、直接转人工或普通文本。
+func (h *wrappedMessageHandler) deliverResponse(ctx context.Context, chatID, senderID, response string) error {
+	// 信息收集完毕：发送确认卡片，用户点击提交后才转人工
+	if strings.HasPrefix(response, conversation.ConfirmPrefix) {
+		summary := strings.TrimPrefix(response, conversation.ConfirmPrefix)
+		return h.sendConfirmCard(ctx, chatID, senderID, summary)
+	}
+
+	// 补充信息收集完毕：追加到原工单话题
+	if strings.HasPrefix(response, conversation.SupplementPrefix) {
+		return h.doEscalation(ctx, chatID, senderID)
+	}
+
+	// 检查是否需要自动转人工（建议/反馈直接提交）
 	if strings.HasPrefix(response, conversation.EscalatePrefix) {
 		userMsg := strings.TrimPrefix(response, conversation.EscalatePrefix)
 		if userMsg != "" {
@@ -169,6 +314,17 @@ func (h *wrappedMessageHandler) HandleMessage(ctx context.Context, chatID, sende
 	return nil
 }
 
+// HandleCardAction 处理卡片回调：表单提交、满意度评价，或确认卡片的提交 / 编辑 / 取消。
+func (h *wrappedMessageHandler) HandleCardAction(ctx context.Context, action feishu.CardAction) (*feishu.CardActionResult, error) {
+	switch action.Action() {
+	case feishu.CardActionFormSubmit:
+		return h.handleFormSubmit(ctx, action)
+	case feishu.CardActionCSATSubmit:
+		return h.handleCSATSubmit(ctx, action)
+	}
+	return h.handleConfirmAction(ctx, action)
+}
+
 // HandleEscalation 处理用户主动转人工请求。
 func (h *wrappedMessageHandler) HandleEscalation(ctx context.Context, chatID, senderID, content string) error {
 	log.Printf("[Escalation] User %s requested escalation in chat %s", senderID, chatID)
@@ -189,29 +345,79 @@ func (h *wrappedMessageHandler) doEscalation(ctx context.Context, chatID, sender
 		return nil
 	}
 
-	// 直接执行转人工，不再重新检查 LLM
-	if err := h.escalationHandler.HandleEscalation(ctx, conv); err != nil {
-		log.Printf("[Escalation] HandleEscalation failed: %v", err)
+	// 技术支持请求的补充信息追加到原工单，不创建新工单
+	if conv.Supplement != nil {
+		return h.submitSupplement(ctx, conv)
+	}
+
+	// 直接执行转人工，不再重新检查 LLM；同一会话重复提交时继续执行已有的任务
+	job, err := h.tickets.GetJob(ctx, models.EscalationJobID(conv))
+	if err != nil {
+		log.Printf("[Escalation] Failed to get escalation job: %v", err)
 		_ = h.feishuClient.SendTextMessage(ctx, chatID, "提交失败，请稍后重试。\nSubmission failed. Please try again later.")
 		return err
 	}
+	if job == nil {
+		job = models.NewEscalationJob(conv)
+		h.classifySeverity(ctx, job)
+	} else {
+		// 摘要发出前用户可能修改了草稿（如提交失败后编辑再提交），按当前会话提交
+		if !job.SummaryPosted() {
+			h.refreshJobConversation(ctx, job, conv)
+		}
+		if job.Status == models.EscalationFailed {
+			// 用户重新提交已失败的任务：重新计数重试次数
+			job.Attempts = 0
+		}
+	}
+	return h.runEscalationJob(ctx, job, conv)
+}
 
-	// 转人工成功后清除会话
-	_ = h.conversationManager.ClearConversation(ctx, chatID)
-	log.Printf("[Escalation] Completed and conversation cleared for chat %s", chatID)
+// refreshJobConversation 摘要发出前按当前草稿更新任务，草稿有修改时重新判断严重程度。
+func (h *wrappedMessageHandler) refreshJobConversation(ctx context.Context, job *models.EscalationJob, conv *models.Conversation) {
+	changed := job.Conversation == nil || !job.Conversation.UpdatedAt.Equal(conv.UpdatedAt)
+	job.Conversation = conv
+	if changed {
+		h.classifySeverity(ctx, job)
+	}
+}
 
-	return nil
+// classifySeverity 在转人工前判断问题的严重程度，并按配置设置工单初始优先级。
+func (h *wrappedMessageHandler) classifySeverity(ctx context.Context, job *models.EscalationJob) {
+	if h.severity == nil {
+		return
+	}
+	s, source := h.severity.Classify(ctx, job.Conversation)
+	if s == "" {
+		return
+	}
+	job.Severity = s
+	job.Priority = h.severity.Priority(s)
+	log.Printf("[Severity] Job %s: severity=%s (%s), priority=%q", job.ID, s, source, job.Priority)
 }
 
-// handleClearContext 清除会话上下文。
+// handleClearContext 清除会话上下文（草稿归档，可通过「恢复草稿」找回）。
 func (h *wrappedMessageHandler) handleClearContext(ctx context.Context, chatID string) error {
 	log.Printf("[Clear] Clearing context for chat %s", chatID)
 
-	if err := h.conversationManager.ClearConversation(ctx, chatID); err != nil {
+	// 同时结束与工单话题的转发
+	if err := h.tickets.ClearActive(ctx, chatID); err != nil {
+		log.Printf("[Clear] Failed to clear active ticket: %v", err)
+	}
+
+	if conv, err := h.conversationManager.GetConversation(ctx, chatID); err == nil {
+		h.discardEscalationJob(ctx, conv)
+	}
+	archived, err := h.conversationManager.ArchiveConversation(ctx, chatID)
+	if err != nil {
 		log.Printf("[Clear] Failed: %v", err)
 		_ = h.feishuClient.SendTextMessage(ctx, chatID, "抱歉，清除上下文时出错了。\nSorry, failed to clear the context.")
 		return err
 	}
 
-	return h.feishuClient.SendTextMessage(ctx, chatID, "上下文已清除，请重新开始描述您的问题。\nContext cleared. Please start describing your issue again.")
+	msg := "上下文已清除，请重新开始描述您的问题。\nContext cleared. Please start describing your issue again."
+	if archived {
+		msg += "\n\n" + restoreHint
+	}
+	return h.feishuClient.SendTextMessage(ctx, chatID, msg)
 }
@@ -0,0 +1,175 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/internal/ticket"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// mergeTicket 处理话题内的「merge 工单号」命令：本工单作为重复问题合并到主工单，
+// 在两个话题内互相回复指向对方的说明，并通知本工单的用户；主工单无法合并时撤销并说明原因。
+func (h *wrappedMessageHandler) mergeTicket(ctx context.Context, msg feishu.ThreadMessage, t *models.Ticket, args []string, reply func(string) error) error {
+	if len(args) == 0 {
+		return reply("请指定主工单号，例如「merge T000045」。/ Please specify the primary ticket, e.g. \"merge T000045\".")
+	}
+	primaryID, ok := ticket.ParseID(args[0])
+	if !ok {
+		return reply(fmt.Sprintf("无效的工单号「%s」。/ Invalid ticket ID.", args[0]))
+	}
+	if primaryID == t.ID {
+		return reply("不能合并到工单自身。/ A ticket cannot be merged into itself.")
+	}
+	if t.MergedInto != "" {
+		return reply(fmt.Sprintf("%s 已合并到 %s。/ Already merged into %s.", t.ID, t.MergedInto, t.MergedInto))
+	}
+	if len(t.Duplicates) > 0 {
+		return reply(fmt.Sprintf("%s 已有重复工单（%s），请将其他工单合并到本工单。/ This ticket already has duplicates, merge the others into it instead.",
+			t.ID, strings.Join(t.Duplicates, "、")))
+	}
+
+	primary, err := h.tickets.Get(ctx, primaryID)
+	if err != nil {
+		log.Printf("[Merge] Failed to get ticket %s: %v", primaryID, err)
+		_ = reply("抱歉，查询工单失败，请稍后重试。/ Failed to look up the ticket, please try again later.")
+		return err
+	}
+	if primary == nil || primary.RootMsgID == "" {
+		return reply(fmt.Sprintf("没有找到工单 %s。/ Ticket %s not found.", primaryID, primaryID))
+	}
+	if refusal := mergeRefusal(primary); refusal != "" {
+		return reply(refusal)
+	}
+
+	// 先将本工单标记为已合并（反向合并会因此被拒绝），主工单确认可以合并后再通知用户并回复
+	prevStatus := t.Status
+	t.MergedInto = primary.ID
+	t.Status = models.TicketMerged
+	t.UpdatedAt = time.Now()
+	if err := h.tickets.Save(ctx, t); err != nil {
+		log.Printf("[Merge] Failed to save ticket %s: %v", t.ID, err)
+		_ = reply("抱歉，更新工单失败，请稍后重试。/ Failed to update the ticket, please try again later.")
+		return err
+	}
+
+	// 主工单在自己的话题锁内异步更新，避免与反向合并互相等待对方的话题锁；
+	// 主工单在此期间已合并或已解决时，再在本工单话题锁内撤销合并
+	dup := *t
+	go func() {
+		var refusal string
+		h.runLocked(primary.RootMsgID, func(ctx context.Context) {
+			refusal = h.linkDuplicate(ctx, primary.ID, &dup)
+		})
+		if refusal != "" {
+			h.runLocked(dup.RootMsgID, func(ctx context.Context) {
+				h.rollbackMerge(ctx, dup.ID, primary.ID, prevStatus, refusal)
+			})
+			return
+		}
+		h.finishMerge(context.Background(), &dup, msg.SenderID)
+	}()
+	return nil
+}
+
+// linkDuplicate 在主工单锁内重新检查主工单仍可合并，记录重复工单，更新根消息并在主工单话题内
+// 回复指向重复工单的说明。返回空字符串表示已合并，否则返回无法合并的原因（中英文）。
+func (h *wrappedMessageHandler) linkDuplicate(ctx context.Context, primaryID string, dup *models.Ticket) string {
+	primary, err := h.tickets.Get(ctx, primaryID)
+	if err != nil || primary == nil {
+		log.Printf("[Merge] Failed to load primary ticket %s: %v", primaryID, err)
+		return fmt.Sprintf("查询工单 %s 失败，请稍后重试。/ Failed to look up %s, please try again later.", primaryID, primaryID)
+	}
+	if refusal := mergeRefusal(primary); refusal != "" {
+		return refusal
+	}
+	if !primary.AddDuplicate(dup.ID) {
+		return ""
+	}
+	primary.UpdatedAt = time.Now()
+	if err := h.tickets.Save(ctx, primary); err != nil {
+		log.Printf("[Merge] Failed to save ticket %s: %v", primary.ID, err)
+		return "更新主工单失败，请稍后重试。/ Failed to update the primary ticket, please try again later."
+	}
+	_ = h.escalationHandler.UpdateTicketMessage(ctx, primary)
+
+	text := fmt.Sprintf("🔗 %s（%s）作为重复问题合并到本工单 / merged as a duplicate\n%s",
+		dup.ID, atText(dup.UserID), dup.ShortTitle(myTicketsTitleLength))
+	if err := h.feishuClient.ReplyTextInThread(ctx, primary.RootMsgID, text); err != nil {
+		log.Printf("[Merge] Failed to post duplicate pointer to %s: %v", primary.ID, err)
+	}
+	return ""
+}
+
+// mergeRefusal 返回主工单不能再接收重复工单的原因（已合并到其他工单或已解决），可以合并时返回空字符串。
+func mergeRefusal(primary *models.Ticket) string {
+	if primary.MergedInto != "" {
+		return fmt.Sprintf("%s 已合并到 %s，请合并到 %s。/ %s is itself a duplicate of %s.",
+			primary.ID, primary.MergedInto, primary.MergedInto, primary.ID, primary.MergedInto)
+	}
+	if primary.IsClosed() {
+		return fmt.Sprintf("%s 已解决，请先在其话题中 reopen。/ %s is resolved, reopen it first.", primary.ID, primary.ID)
+	}
+	return ""
+}
+
+// finishMerge 在主工单记录重复工单后更新重复工单的根消息，通知其用户并在其话题内回复。
+func (h *wrappedMessageHandler) finishMerge(ctx context.Context, dup *models.Ticket, operatorID string) {
+	log.Printf("[Merge] Ticket %s merged into %s by %s", dup.ID, dup.MergedInto, operatorID)
+	_ = h.escalationHandler.UpdateTicketMessage(ctx, dup)
+
+	notice := fmt.Sprintf("🔗 您的工单 %s 与工单 %s 是同一问题，已合并处理，解决后会通知您。\nYour ticket %s has been merged into %s as the same issue. We will let you know when it is resolved.",
+		dup.ID, dup.MergedInto, dup.ID, dup.MergedInto)
+	if err := h.feishuClient.SendTextMessage(ctx, dup.ChatID, notice); err != nil {
+		log.Printf("[Merge] Failed to notify user of %s: %v", dup.ID, err)
+	}
+	reply := fmt.Sprintf("🔗 %s 已作为重复问题合并到 %s，后续请在 %s 话题中跟进。/ Merged into %s as a duplicate.",
+		dup.ID, dup.MergedInto, dup.MergedInto, dup.MergedInto)
+	if err := h.feishuClient.ReplyTextInThread(ctx, dup.RootMsgID, reply); err != nil {
+		log.Printf("[Merge] Failed to reply in %s: %v", dup.ID, err)
+	}
+}
+
+// rollbackMerge 在主工单无法合并时撤销重复工单的合并状态，并在其话题内说明原因。
+func (h *wrappedMessageHandler) rollbackMerge(ctx context.Context, dupID, primaryID string, prevStatus models.TicketStatus, refusal string) {
+	dup, err := h.tickets.Get(ctx, dupID)
+	if err != nil || dup == nil {
+		log.Printf("[Merge] Failed to load ticket %s for rollback: %v", dupID, err)
+		return
+	}
+	if dup.MergedInto == primaryID {
+		dup.MergedInto = ""
+		dup.Status = prevStatus
+		dup.UpdatedAt = time.Now()
+		if err := h.tickets.Save(ctx, dup); err != nil {
+			log.Printf("[Merge] Failed to roll back ticket %s: %v", dup.ID, err)
+			return
+		}
+	}
+	log.Printf("[Merge] Merge of %s into %s rolled back: %s", dup.ID, primaryID, refusal)
+	text := fmt.Sprintf("⚠️ %s 未合并到 %s：%s", dup.ID, primaryID, refusal)
+	if err := h.feishuClient.ReplyTextInThread(ctx, dup.RootMsgID, text); err != nil {
+		log.Printf("[Merge] Failed to reply in %s: %v", dup.ID, err)
+	}
+}
+
+// notifyDuplicates 在主工单解决后通知所有重复工单的用户。
+func (h *wrappedMessageHandler) notifyDuplicates(ctx context.Context, primary *models.Ticket) {
+	for _, id := range primary.Duplicates {
+		dup, err := h.tickets.Get(ctx, id)
+		if err != nil || dup == nil || dup.MergedInto != primary.ID {
+			continue
+		}
+		msg := fmt.Sprintf("✅ 您反馈的问题（工单 %s，已合并到 %s）已解决。如仍有问题，请发送「重新打开 %s」。\nYour issue (ticket %s, merged into %s) has been resolved. Send \"reopen %s\" if it happens again.",
+			dup.ID, primary.ID, dup.ID, dup.ID, primary.ID, dup.ID)
+		if err := h.feishuClient.SendTextMessage(ctx, dup.ChatID, msg); err != nil {
+			log.Printf("[Merge] Failed to notify user of duplicate %s: %v", dup.ID, err)
+			continue
+		}
+		log.Printf("[Merge] Notified user of duplicate %s (primary %s resolved)", dup.ID, primary.ID)
+	}
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/internal/feishu"
+)
+
+// groupHelpText 是转人工群内（话题外）命令的帮助信息。
+const groupHelpText = `群内可用命令 / Group commands（@机器人 + 命令）：
+- oncall / 值班（查看本群当前的值班人员）
+- away / 离开（暂停自动分配给自己）、back / 回来（恢复）
+工单命令请在工单话题内使用。/ Ticket commands work inside ticket threads.`
+
+// HandleGroupCommand 处理转人工群内（话题外）@机器人 的命令：oncall 查看本群各路由当前的值班人员，
+// away / back 设置自己离开或回来。
+func (h *wrappedMessageHandler) HandleGroupCommand(ctx context.Context, msg feishu.ThreadMessage) error {
+	if !h.escalationHandler.IsEscalationGroup(msg.ChatID) {
+		return nil
+	}
+	cmd, ok := parseAgentCommand(msg.Content)
+	switch {
+	case ok && cmd.kind == agentOnCall:
+		return h.feishuClient.ReplyMessage(ctx, msg.MessageID, h.buildOnCallReport(h.escalationHandler.GroupRoutes(msg.ChatID)))
+	case ok && (cmd.kind == agentAway || cmd.kind == agentBack):
+		return h.feishuClient.ReplyMessage(ctx, msg.MessageID, h.setAway(ctx, msg.SenderID, cmd.kind == agentAway))
+	}
+	return h.feishuClient.ReplyMessage(ctx, msg.MessageID, groupHelpText)
+}
+
+// buildOnCallReport 构建各路由当前的值班人员和交接时间（路由名称为空表示默认群组）。
+func (h *wrappedMessageHandler) buildOnCallReport(routes []string) string {
+	now := time.Now()
+	loc := h.oncall.Location()
+
+	var sb strings.Builder
+	sb.WriteString("👷 当前值班 / On call")
+	for _, route := range routes {
+		name := route
+		if name == "" {
+			name = "默认 / default"
+		}
+		shift := h.oncall.Current(route, now)
+		if shift.User == "" {
+			sb.WriteString(fmt.Sprintf("\n  %s: 未配置 / Not configured", name))
+			continue
+		}
+		kind := ""
+		if shift.Override {
+			kind = "（替班 / override）"
+		}
+		sb.WriteString(fmt.Sprintf("\n  %s: %s%s，至 %s %s / until",
+			name, atText(shift.User), kind, shift.Until.In(loc).Format("01-02 15:04"), loc))
+	}
+	return sb.String()
+}
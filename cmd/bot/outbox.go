@@ -0,0 +1,155 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/internal/handler"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// outboxCheckInterval 是检查待重试转人工任务的间隔。
+const outboxCheckInterval = 15 * time.Second
+
+// runEscalationJob 执行转人工任务；conv 为任务对应的当前会话（已清除或已开始新草稿时为 nil）。
+// 摘要发出后完成会话（更新确认卡片、清除草稿）；其余步骤失败时由后台任务继续重试。
+// 非工作时间暂缓的任务同样结束会话，上班时由后台任务发出摘要。
+func (h *wrappedMessageHandler) runEscalationJob(ctx context.Context, job *models.EscalationJob, conv *models.Conversation) error {
+	t, err := h.escalationHandler.HandleEscalation(ctx, job)
+	if errors.Is(err, handler.ErrEscalationHeld) {
+		if conv != nil {
+			h.updateConfirmCard(ctx, conv, feishu.BuildConfirmResultCard("🌙 已受理，上班后提交 / Queued · "+job.TicketID, feishu.CardTemplateGrey, conv.GetUserSummary()))
+			_ = h.conversationManager.ClearConversation(ctx, conv.ChatID)
+			log.Printf("[Escalation] Job %s held, conversation cleared for chat %s", job.ID, conv.ChatID)
+		}
+		return nil
+	}
+	if t != nil && conv != nil {
+		h.completeEscalation(ctx, conv, t)
+	}
+	if err == nil || t != nil {
+		return err
+	}
+
+	// 摘要尚未发出：首次失败时告知用户将自动重试，重试次数用尽时请用户重新提交
+	chatID := job.Conversation.ChatID
+	switch {
+	case job.Status == models.EscalationFailed:
+		log.Printf("[Escalation] Job %s gave up: %v", job.ID, err)
+		if conv != nil {
+			h.updateConfirmCard(ctx, conv, feishu.BuildConfirmCard(conv.GetUserSummary(), 0))
+			_ = h.feishuClient.SendTextMessage(ctx, chatID, "提交失败，请稍后重新提交。\nSubmission failed. Please submit again later.")
+		} else {
+			_ = h.feishuClient.SendTextMessage(ctx, chatID, "抱歉，您之前的问题提交失败，请重新描述问题后提交。\nSorry, your earlier report could not be submitted. Please describe your issue again.")
+		}
+	case job.Attempts == 1:
+		if conv != nil {
+			h.updateConfirmCard(ctx, conv, feishu.BuildConfirmResultCard("⏳ 正在重试 / Retrying", feishu.CardTemplateGrey, conv.GetUserSummary()))
+		}
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, "提交暂未成功，系统会自动重试，成功后会通知您。\nSubmission has not gone through yet. We'll keep retrying and let you know once it's submitted.")
+	}
+	return err
+}
+
+// completeEscalation 在摘要发出后完成会话：确认卡片更新为已提交，清除草稿并开启 follow_up_window。
+func (h *wrappedMessageHandler) completeEscalation(ctx context.Context, conv *models.Conversation, t *models.Ticket) {
+	h.updateConfirmCard(ctx, conv, feishu.BuildConfirmResultCard("✅ 已提交 / Submitted · "+t.ID, feishu.CardTemplateGreen, conv.GetUserSummary()))
+	h.openFollowUpWindow(ctx, t)
+
+	// 转人工成功后清除会话
+	_ = h.conversationManager.ClearConversation(ctx, conv.ChatID)
+	log.Printf("[Escalation] Completed and conversation cleared for chat %s", conv.ChatID)
+}
+
+// retryEscalations 定时任务：继续执行到期的转人工任务（失败重试、进程退出后未完成的任务）。
+func (h *wrappedMessageHandler) retryEscalations(ctx context.Context) {
+	ids, err := h.tickets.ClaimDueJobs(ctx, time.Now())
+	if err != nil {
+		log.Printf("[Escalation] Failed to claim due jobs: %v", err)
+	}
+
+	// 暂缓到上班时间的工单：发出后按群组汇总
+	held := make(map[string][]string)
+	for _, id := range ids {
+		job, err := h.tickets.GetJob(ctx, id)
+		if err != nil || job == nil || job.Conversation == nil {
+			log.Printf("[Escalation] Skipping job %s: %v", id, err)
+			continue
+		}
+
+		chatID := job.Conversation.ChatID
+		h.runLocked(chatID, func(ctx context.Context) {
+			// 锁内重新读取，期间用户可能已重新提交
+			job, err := h.tickets.GetJob(ctx, id)
+			if err != nil || job == nil || job.Status != models.EscalationPending {
+				return
+			}
+			conv, err := h.conversationManager.GetConversation(ctx, chatID)
+			if err != nil {
+				// 无法确认草稿是否已被清除，放回待执行集合稍后再试
+				log.Printf("[Escalation] Failed to get conversation for job %s: %v", id, err)
+				job.NextAttemptAt = time.Now().Add(outboxCheckInterval)
+				if err := h.tickets.SaveJob(ctx, job); err != nil {
+					log.Printf("[Escalation] %v", err)
+				}
+				return
+			}
+			if conv != nil && models.EscalationJobID(conv) != job.ID {
+				conv = nil
+			}
+			posted := job.SummaryPosted()
+			switch {
+			case conv != nil && !posted:
+				// 摘要发出前按当前草稿提交
+				h.refreshJobConversation(ctx, job, conv)
+			case conv == nil && !posted && job.HeldUntil.IsZero():
+				// 草稿在摘要发出前已被清除 / 取消（暂缓的任务已受理，不受影响）
+				log.Printf("[Escalation] Dropping job %s: draft was cleared before the summary was posted", job.ID)
+				if err := h.tickets.DeleteJob(ctx, job.ID); err != nil {
+					log.Printf("[Escalation] %v", err)
+				}
+				return
+			}
+			_ = h.runEscalationJob(ctx, job, conv)
+			if !posted && job.SummaryPosted() && !job.HeldUntil.IsZero() {
+				held[job.Target.GroupID] = append(held[job.Target.GroupID], job.TicketID)
+			}
+		})
+	}
+	h.postHeldBatches(ctx, held)
+}
+
+// discardEscalationJob 草稿被清除、取消或过期时丢弃尚未发出摘要的转人工任务，
+// 避免后台重试按已清除的草稿提交，恢复草稿后重新提交时也会创建新任务（暂缓的任务已受理，不丢弃）。
+func (h *wrappedMessageHandler) discardEscalationJob(ctx context.Context, conv *models.Conversation) {
+	if conv == nil {
+		return
+	}
+	job, err := h.tickets.GetJob(ctx, models.EscalationJobID(conv))
+	if err != nil || job == nil || job.SummaryPosted() || !job.HeldUntil.IsZero() {
+		return
+	}
+	if err := h.tickets.DeleteJob(ctx, job.ID); err != nil {
+		log.Printf("[Escalation] %v", err)
+		return
+	}
+	log.Printf("[Escalation] Discarded unposted job %s for chat %s", job.ID, conv.ChatID)
+}
+
+// postHeldBatches 在群组中汇总上班时发出的暂缓工单（群组 chat_id → 工单号）。
+func (h *wrappedMessageHandler) postHeldBatches(ctx context.Context, held map[string][]string) {
+	for groupID, ids := range held {
+		text := fmt.Sprintf("🌅 非工作时间收到的 %d 个问题已发出 / %d issues received outside business hours: %s",
+			len(ids), len(ids), strings.Join(ids, "、"))
+		if err := h.feishuClient.SendTextMessage(ctx, groupID, text); err != nil {
+			log.Printf("[Escalation] Failed to post held batch to %s: %v", groupID, err)
+			continue
+		}
+		log.Printf("[Escalation] Posted %d held tickets to group %s", len(ids), groupID)
+	}
+}
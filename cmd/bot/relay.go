@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/internal/conversation"
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// newIssueCommands 是结束转发窗口、开始反馈新问题的命令（精确匹配，忽略大小写）。
+var newIssueCommands = []string{"新问题", "新的问题", "新反馈", "new issue", "new ticket"}
+
+// isNewIssueCommand 判断消息是否为开始新问题的命令。
+func isNewIssueCommand(content string) bool {
+	lower := strings.ToLower(strings.TrimSpace(content))
+	for _, c := range newIssueCommands {
+		if lower == c {
+			return true
+		}
+	}
+	return false
+}
+
+// HandleThreadMessage 处理转人工群组中工单话题内的回复：@机器人 的消息作为工单命令执行，
+// 其他回复转发到用户私聊，并在 relay_window 内把用户的后续消息回复到该话题。
+func (h *wrappedMessageHandler) HandleThreadMessage(ctx context.Context, msg feishu.ThreadMessage) error {
+	if !h.escalationHandler.IsEscalationGroup(msg.ChatID) {
+		return nil
+	}
+
+	t, err := h.tickets.GetByRootMessage(ctx, msg.RootID)
+	if err != nil {
+		log.Printf("[Relay] Failed to get ticket for thread %s: %v", msg.RootID, err)
+		return err
+	}
+	if t == nil {
+		return nil
+	}
+	// 技术支持在话题内的首条消息（回复或命令）计为首次响应
+	if msg.SenderID != t.UserID {
+		h.recordFirstResponse(ctx, t)
+	}
+
+	// @机器人 的消息是技术支持的工单命令，不转发
+	if msg.MentionsBot {
+		return h.handleAgentCommand(ctx, msg, t)
+	}
+	// 用户本人在群里的回复无需转发
+	if msg.SenderID == t.UserID {
+		return nil
+	}
+
+	if err := h.escalationHandler.RelayToUser(ctx, t, msg); err != nil {
+		log.Printf("[Relay] Failed to relay thread message %s to user: %v", msg.MessageID, err)
+		return err
+	}
+	log.Printf("[Relay] Thread message %s relayed to chat %s (ticket %s)", msg.MessageID, t.ChatID, t.ID)
+
+	if window := h.cfg.Bot.RelayWindowDuration(); window > 0 {
+		if err := h.tickets.SetActive(ctx, t.ChatID, t.ID, window); err != nil {
+			log.Printf("[Relay] Failed to open relay window: %v", err)
+		}
+	}
+	return nil
+}
+
+// followUpExtension 返回用户在转发窗口内发送消息后窗口延长的时长（relay_window 与
+// follow_up_window 中较长者），0 表示两者均未启用。
+func (h *wrappedMessageHandler) followUpExtension() time.Duration {
+	relay, followUp := h.cfg.Bot.RelayWindowDuration(), h.cfg.Bot.FollowUpWindowDuration()
+	if followUp > relay {
+		return followUp
+	}
+	return relay
+}
+
+// openFollowUpWindow 在提交成功后开启 follow_up_window：期间用户的消息和文件追加到该工单话题。
+func (h *wrappedMessageHandler) openFollowUpWindow(ctx context.Context, t *models.Ticket) {
+	window := h.cfg.Bot.FollowUpWindowDuration()
+	if window <= 0 || t.RootMsgID == "" {
+		return
+	}
+	if err := h.tickets.SetActive(ctx, t.ChatID, t.ID, window); err != nil {
+		log.Printf("[Relay] Failed to open follow-up window for ticket %s: %v", t.ID, err)
+		return
+	}
+	hint := fmt.Sprintf("📎 接下来 %d 分钟内发送的消息和文件会自动添加到工单 %s；如需反馈新的问题，请发送「新问题」。\n"+
+		"Messages and files you send in the next %d minutes will be added to ticket %s. Send \"new issue\" to report a different problem.",
+		h.cfg.Bot.FollowUpWindow, t.ID, h.cfg.Bot.FollowUpWindow, t.ID)
+	if err := h.feishuClient.SendTextMessage(ctx, t.ChatID, hint); err != nil {
+		log.Printf("[Relay] Failed to send follow-up hint: %v", err)
+	}
+}
+
+// handleNewIssue 结束转发窗口（后续消息不再追加到之前的工单），并发送欢迎语开始新的反馈。
+func (h *wrappedMessageHandler) handleNewIssue(ctx context.Context, chatID string) error {
+	if err := h.tickets.ClearActive(ctx, chatID); err != nil {
+		log.Printf("[Relay] Failed to clear active ticket: %v", err)
+	}
+	log.Printf("[Relay] New issue requested, relay window closed for chat %s", chatID)
+	return h.feishuClient.SendTextMessage(ctx, chatID, conversation.WelcomeMessage())
+}
+
+// relayFollowUp 在转发窗口（提交后的 follow_up_window 或技术支持回复后的 relay_window）内将用户消息
+// 回复到正在沟通的工单话题，返回是否已处理。用户已开始新的草稿时不转发，按正常信息收集流程处理。
+func (h *wrappedMessageHandler) relayFollowUp(ctx context.Context, chatID, messageID, content, fileKey string) (bool, error) {
+	window := h.followUpExtension()
+	if window <= 0 {
+		return false, nil
+	}
+
+	t, err := h.tickets.GetActive(ctx, chatID)
+	if err != nil {
+		log.Printf("[Relay] Failed to get active ticket: %v", err)
+		return false, nil
+	}
+	if t == nil {
+		return false, nil
+	}
+	if conv, err := h.conversationManager.GetConversation(ctx, chatID); err != nil || (conv != nil && conv.HasDraft()) {
+		return false, nil
+	}
+
+	if err := h.escalationHandler.RelayToThread(ctx, t, messageID, content, fileKey); err != nil {
+		log.Printf("[Relay] Failed to relay message %s to ticket %s: %v", messageID, t.ID, err)
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, "抱歉，消息未能转给技术支持，请稍后重试。\nSorry, failed to pass your message to the support team. Please try again later.")
+		return true, err
+	}
+
+	if err := h.tickets.SetActive(ctx, chatID, t.ID, window); err != nil {
+		log.Printf("[Relay] Failed to extend relay window: %v", err)
+	}
+	if fileKey != "" {
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, fmt.Sprintf("📎 文件已添加到工单 %s。\nFile added to ticket %s.", t.ID, t.ID))
+	}
+	return true, nil
+}
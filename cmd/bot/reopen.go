@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/even/feishu-bot/internal/ticket"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// reopenCommands 是用户重新打开工单的命令（忽略大小写），后面可跟工单号和说明。
+var reopenCommands = []string{"重新打开", "重开", "reopen"}
+
+// parseReopenCommand 解析「重新打开 [工单号] [说明]」，返回工单号（省略时为空）和说明。
+// 省略工单号时只接受单独的命令，避免把以 "reopen" 开头的问题描述当作命令。
+func parseReopenCommand(content string) (id, note string, ok bool) {
+	fields := strings.Fields(content)
+	if len(fields) == 0 || !isReopenWord(fields[0]) {
+		return "", "", false
+	}
+	rest := fields[1:]
+	if len(rest) > 0 {
+		if parsed, ok := ticket.ParseID(rest[0]); ok {
+			return parsed, strings.Join(rest[1:], " "), true
+		}
+		return "", "", false
+	}
+	return "", "", true
+}
+
+// isReopenWord 判断词是否为重新打开命令。
+func isReopenWord(word string) bool {
+	for _, c := range reopenCommands {
+		if strings.EqualFold(word, c) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleUserReopen 处理用户的重新打开命令：找到用户的工单（未指定时为最近结束的工单），
+// 已合并的工单重新打开其主工单。工单在话题锁内异步更新（当前持有私聊锁，
+// 话题命令会先持有话题锁再获取私聊锁，同步获取话题锁可能互相等待）。
+func (h *wrappedMessageHandler) handleUserReopen(ctx context.Context, chatID, senderID, id, note string) error {
+	t, err := h.findReopenTicket(ctx, senderID, id)
+	if err != nil {
+		return h.replyTicketError(ctx, chatID, err)
+	}
+	if t == nil {
+		if id != "" {
+			return h.feishuClient.SendTextMessage(ctx, chatID, fmt.Sprintf("没有找到工单 %s。\nTicket %s not found.", id, id))
+		}
+		return h.feishuClient.SendTextMessage(ctx, chatID, "没有可以重新打开的工单。\nYou have no resolved tickets to reopen.")
+	}
+
+	if t.MergedInto != "" {
+		primary, err := h.tickets.Get(ctx, t.MergedInto)
+		if err != nil {
+			return h.replyTicketError(ctx, chatID, err)
+		}
+		if primary == nil {
+			return h.feishuClient.SendTextMessage(ctx, chatID, fmt.Sprintf("没有找到工单 %s。\nTicket %s not found.", t.MergedInto, t.MergedInto))
+		}
+		t = primary
+	}
+	if t.Status != models.TicketResolved || t.RootMsgID == "" {
+		return h.feishuClient.SendTextMessage(ctx, chatID, fmt.Sprintf("工单 %s 仍在处理中，无需重新打开。\nTicket %s is still open.", t.ID, t.ID))
+	}
+
+	ticketID, rootMsgID := t.ID, t.RootMsgID
+	go h.runLocked(rootMsgID, func(ctx context.Context) {
+		h.reopenByUser(ctx, ticketID, chatID, senderID, note)
+	})
+	return nil
+}
+
+// findReopenTicket 查找用户要重新打开的工单：指定工单号时须为用户本人的工单，否则为最近结束的工单。
+func (h *wrappedMessageHandler) findReopenTicket(ctx context.Context, senderID, id string) (*models.Ticket, error) {
+	if id != "" {
+		t, err := h.tickets.Get(ctx, id)
+		if err != nil || t == nil || t.UserID != senderID {
+			return nil, err
+		}
+		return t, nil
+	}
+
+	tickets, err := h.tickets.ListByUser(ctx, senderID, myTicketsLimit)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tickets {
+		if t.IsClosed() {
+			return t, nil
+		}
+	}
+	return nil, nil
+}
+
+// reopenByUser 在话题锁内重新打开工单：更新根消息，在原话题内 @负责人 说明用户重新打开，
+// 并通知用户（工单本人重新打开时开启 follow_up_window，后续消息追加到该话题）。
+func (h *wrappedMessageHandler) reopenByUser(ctx context.Context, id, chatID, senderID, note string) {
+	t, err := h.tickets.Get(ctx, id)
+	if err != nil || t == nil {
+		log.Printf("[Ticket] Failed to load ticket %s for reopen: %v", id, err)
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, "抱歉，重新打开工单失败，请稍后重试。\nSorry, failed to reopen the ticket. Please try again later.")
+		return
+	}
+	// 锁内重新检查：期间可能已被技术支持重新打开
+	if t.Status != models.TicketResolved {
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, fmt.Sprintf("工单 %s 仍在处理中，无需重新打开。\nTicket %s is still open.", t.ID, t.ID))
+		return
+	}
+
+	mention := t.Assignee
+	if mention == "" {
+		mention = t.ResolvedBy
+	}
+	t.Reopen()
+	if err := h.tickets.Save(ctx, t); err != nil {
+		log.Printf("[Ticket] Failed to save ticket %s: %v", t.ID, err)
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, "抱歉，重新打开工单失败，请稍后重试。\nSorry, failed to reopen the ticket. Please try again later.")
+		return
+	}
+	log.Printf("[Ticket] Ticket %s reopened by user %s", t.ID, senderID)
+	_ = h.escalationHandler.UpdateTicketMessage(ctx, t)
+
+	text := fmt.Sprintf("🔁 %s 重新打开了工单 %s / Reopened by user", atText(senderID), t.ID)
+	if mention != "" {
+		text = atText(mention) + " " + text
+	}
+	if note != "" {
+		text += "\n" + note
+	}
+	if err := h.feishuClient.ReplyTextInThread(ctx, t.RootMsgID, text); err != nil {
+		log.Printf("[Ticket] Failed to post reopen to ticket %s: %v", t.ID, err)
+	}
+
+	_ = h.feishuClient.SendTextMessage(ctx, chatID, fmt.Sprintf("🔁 工单 %s 已重新打开，技术支持会尽快跟进。\nTicket %s has been reopened. The support team will follow up soon.", t.ID, t.ID))
+	if t.ChatID == chatID {
+		h.openFollowUpWindow(ctx, t)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestParseReopenCommand(t *testing.T) {
+	tests := []struct {
+		text string
+		ok   bool
+		id   string
+		note string
+	}{
+		{text: "重新打开", ok: true},
+		{text: " 重开 ", ok: true},
+		{text: "Reopen", ok: true},
+		{text: "reopen T123", ok: true, id: "T000123"},
+		{text: "重新打开 #T000042 还是连不上", ok: true, id: "T000042", note: "还是连不上"},
+		{text: "重开 123 升级后又出现了", ok: true, id: "T000123", note: "升级后又出现了"},
+		{text: "reopen #5 still broken after update", ok: true, id: "T000005", note: "still broken after update"},
+
+		{text: "reopen the app and it crashes"},
+		{text: "reopen T0 please"},
+		{text: "重新打开眼镜后蓝牙断开"},
+		{text: "我想重新打开工单"},
+		{text: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			id, note, ok := parseReopenCommand(tt.text)
+			if ok != tt.ok || id != tt.id || note != tt.note {
+				t.Errorf("parseReopenCommand(%q) = (%q, %q, %v), want (%q, %q, %v)",
+					tt.text, id, note, ok, tt.id, tt.note, tt.ok)
+			}
+		})
+	}
+}
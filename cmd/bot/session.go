@@ -0,0 +1,115 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/internal/config"
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// sessionCheckInterval 是检查草稿提醒和过期的间隔。
+const sessionCheckInterval = time.Minute
+
+// remindIdleSessions 定时任务：提醒即将过期的草稿补充信息或确认提交。
+func (h *wrappedMessageHandler) remindIdleSessions(ctx context.Context) {
+	now := time.Now()
+	chatIDs, err := h.conversationManager.ClaimDueSessionReminders(ctx, now)
+	if err != nil {
+		log.Printf("[Session] Failed to claim due reminders: %v", err)
+	}
+
+	remindAfter := h.cfg.Bot.SessionTimeoutDuration() - h.cfg.Bot.SessionReminderDuration()
+	for _, chatID := range chatIDs {
+		h.runLocked(chatID, func(ctx context.Context) {
+			conv := h.idleDraft(ctx, chatID, now, remindAfter)
+			if conv == nil {
+				return
+			}
+			log.Printf("[Session] Reminding chat %s before expiry", chatID)
+			_ = h.feishuClient.SendTextMessage(ctx, chatID, buildReminderMessage(conv, h.cfg.Bot))
+		})
+	}
+}
+
+// expireIdleSessions 定时任务：处理已过期的草稿（通知用户或自动提交）。
+// 未配置 session_timeout 时草稿随 redis.expiration 过期，只归档并通知，不自动提交。
+func (h *wrappedMessageHandler) expireIdleSessions(ctx context.Context) {
+	now := time.Now()
+	chatIDs, err := h.conversationManager.ClaimDueSessionExpiries(ctx, now)
+	if err != nil {
+		log.Printf("[Session] Failed to claim due expiries: %v", err)
+	}
+
+	for _, chatID := range chatIDs {
+		h.runLocked(chatID, func(ctx context.Context) {
+			conv := h.idleDraft(ctx, chatID, now, h.cfg.DraftExpiryDuration())
+			if conv == nil {
+				return
+			}
+
+			if h.cfg.Bot.SessionTimeout > 0 && h.cfg.Bot.SessionExpiryAction == config.SessionExpirySubmit {
+				log.Printf("[Session] Draft expired in chat %s, auto-submitting", chatID)
+				_ = h.feishuClient.SendTextMessage(ctx, chatID, "您的草稿长时间未更新，已按当前信息自动提交。\nYour draft was idle for too long and has been submitted with the info collected so far.")
+				_ = h.doEscalation(ctx, chatID, conv.SenderID)
+				return
+			}
+
+			log.Printf("[Session] Draft expired in chat %s, clearing", chatID)
+			h.updateConfirmCard(ctx, conv, feishu.BuildConfirmResultCard("⌛ 草稿已过期 / Draft expired", feishu.CardTemplateGrey, ""))
+			h.discardEscalationJob(ctx, conv)
+			if _, err := h.conversationManager.ArchiveConversation(ctx, chatID); err != nil {
+				log.Printf("[Session] Failed to clear expired draft: %v", err)
+				return
+			}
+			_ = h.feishuClient.SendTextMessage(ctx, chatID, "您的草稿长时间未更新，已过期清除。如仍需帮助，请重新描述您的问题。\nYour draft expired after being idle for too long. Describe your issue again whenever you need help.\n\n"+restoreHint)
+		})
+	}
+}
+
+// idleDraft 返回闲置超过 idle 的非空问题草稿；会话不存在、为空、期间有新活动或已是建议模式时返回 nil。
+func (h *wrappedMessageHandler) idleDraft(ctx context.Context, chatID string, now time.Time, idle time.Duration) *models.Conversation {
+	conv, err := h.conversationManager.GetConversation(ctx, chatID)
+	if err != nil {
+		log.Printf("[Session] Failed to get conversation: %v", err)
+		return nil
+	}
+	if conv == nil || conv.Mode == models.ModeSuggestion || !conv.HasDraft() {
+		return nil
+	}
+	// 认领后用户可能又发了消息（已重新安排提醒/过期时间）
+	if conv.UpdatedAt.Add(idle).After(now) {
+		return nil
+	}
+	return conv
+}
+
+// buildReminderMessage 构建过期提醒：等待确认时提醒提交，否则列出仍缺失的信息。
+func buildReminderMessage(conv *models.Conversation, bot config.BotConfig) string {
+	var sb strings.Builder
+	minutes := bot.SessionReminder
+	if bot.SessionExpiryAction == config.SessionExpirySubmit {
+		sb.WriteString(fmt.Sprintf("⏰ 您的草稿将在 %d 分钟后按当前信息自动提交。/ Your draft will be submitted as-is in %d min.\n\n", minutes, minutes))
+	} else {
+		sb.WriteString(fmt.Sprintf("⏰ 您的草稿将在 %d 分钟后过期。/ Your draft will expire in %d min.\n\n", minutes, minutes))
+	}
+
+	if conv.AwaitingConfirmation() {
+		sb.WriteString("请点击确认卡片上的「提交」，或回复「提交」。\nPlease tap \"Submit\" on the confirmation card, or reply \"submit\".")
+		return sb.String()
+	}
+
+	if missing := conv.GetMissingFields(); len(missing) > 0 {
+		sb.WriteString("还需要以下信息 / Still need:\n")
+		for _, name := range missing {
+			sb.WriteString(fmt.Sprintf("  - %s\n", name))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("回复「提交」或 \"submit\" 可直接提交当前信息。\nReply \"submit\" to submit current info directly.")
+	return sb.String()
+}
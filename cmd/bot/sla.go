@@ -0,0 +1,197 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// slaCheckInterval 是检查工单 SLA 的间隔。
+const slaCheckInterval = time.Minute
+
+// slaKindLabels 是 SLA 考核项的双语显示名。
+var slaKindLabels = map[string]string{
+	models.SLAFirstResponse: "首次响应 / First response",
+	models.SLAResolution:    "解决 / Resolution",
+}
+
+// checkSLA 定时任务：检查未解决工单的首次响应和解决时限，即将超时或已超时时在话题内提醒。
+func (h *wrappedMessageHandler) checkSLA(ctx context.Context) {
+	tickets, err := h.tickets.ListOpen(ctx)
+	if err != nil {
+		log.Printf("[SLA] Failed to list open tickets: %v", err)
+		return
+	}
+
+	now := time.Now()
+	for _, t := range tickets {
+		if t.RootMsgID == "" {
+			continue
+		}
+		// 与话题内消息共用锁，避免与工单命令同时修改工单
+		id := t.ID
+		h.runLocked(t.RootMsgID, func(ctx context.Context) {
+			h.checkTicketSLA(ctx, id, now)
+		})
+	}
+}
+
+// checkTicketSLA 检查单个工单的 SLA（在话题锁内重新读取工单）。
+func (h *wrappedMessageHandler) checkTicketSLA(ctx context.Context, id string, now time.Time) {
+	t, err := h.tickets.Get(ctx, id)
+	if err != nil || t == nil || t.IsClosed() {
+		return
+	}
+	target, ok := h.cfg.SLA.Target(t.Priority)
+	if !ok {
+		return
+	}
+
+	changed := false
+	if limit := target.FirstResponseDuration(); limit > 0 && t.FirstResponseAt.IsZero() {
+		changed = h.checkSLADeadline(ctx, t, models.SLAFirstResponse, t.SLAClockStart(models.SLAFirstResponse).Add(limit), now) || changed
+	}
+	if limit := target.ResolutionDuration(); limit > 0 {
+		changed = h.checkSLADeadline(ctx, t, models.SLAResolution, t.SLAClockStart(models.SLAResolution).Add(limit), now) || changed
+	}
+	if !changed {
+		return
+	}
+	if err := h.tickets.Save(ctx, t); err != nil {
+		log.Printf("[SLA] Failed to save ticket %s: %v", t.ID, err)
+	}
+}
+
+// checkSLADeadline 对单个考核项发送即将超时提醒或超时通知（每项各一次），返回工单是否有变化。
+func (h *wrappedMessageHandler) checkSLADeadline(ctx context.Context, t *models.Ticket, kind string, deadline, now time.Time) bool {
+	label := slaKindLabels[kind]
+	priority := h.slaPriority(t)
+
+	var text string
+	switch {
+	case !now.Before(deadline):
+		if !t.MarkSLABreached(kind) {
+			return false
+		}
+		if err := h.tickets.IncrSLABreach(ctx, kind, priority); err != nil {
+			log.Printf("[SLA] %v", err)
+		}
+		log.Printf("[SLA] Ticket %s breached %s SLA (%s)", t.ID, kind, priority)
+		text = fmt.Sprintf("🚨 %s 已超过%s时限（%s，截止 %s）/ SLA breached",
+			t.ID, label, priority, deadline.In(h.cfg.Bot.Location()).Format("01-02 15:04"))
+
+	case !now.Before(deadline.Add(-h.cfg.SLA.ReminderBeforeDuration())):
+		if t.SLABreachedFor(kind) || !t.MarkSLAReminded(kind) {
+			return false
+		}
+		log.Printf("[SLA] Ticket %s %s SLA due at %s", t.ID, kind, deadline.Format(time.RFC3339))
+		text = fmt.Sprintf("⏰ %s 即将超过%s时限（%s），剩余约 %d 分钟 / SLA due soon",
+			t.ID, label, priority, int(deadline.Sub(now).Minutes())+1)
+
+	default:
+		return false
+	}
+
+	if mentions := h.slaMentions(t); mentions != "" {
+		text = mentions + " " + text
+	}
+	if err := h.feishuClient.ReplyTextInThread(ctx, t.RootMsgID, text); err != nil {
+		log.Printf("[SLA] Failed to remind ticket %s: %v", t.ID, err)
+	}
+	return true
+}
+
+// slaMentions 返回 SLA 提醒需要 @ 的人：负责人；无负责人时为工单路由当前的值班人员，
+// 未配置轮值时为 sla.oncall。
+func (h *wrappedMessageHandler) slaMentions(t *models.Ticket) string {
+	ids := h.cfg.SLA.OnCall
+	if user := h.oncall.Current(t.Route, time.Now()).User; user != "" {
+		ids = []string{user}
+	}
+	if t.Assignee != "" {
+		ids = []string{t.Assignee}
+	}
+	mentions := make([]string, 0, len(ids))
+	for _, id := range ids {
+		mentions = append(mentions, atText(id))
+	}
+	return strings.Join(mentions, " ")
+}
+
+// slaPriority 返回计算 SLA 使用的优先级（未设置时为默认优先级）。
+func (h *wrappedMessageHandler) slaPriority(t *models.Ticket) string {
+	if t.Priority == "" {
+		return h.cfg.SLA.DefaultPriority
+	}
+	return t.Priority
+}
+
+// recordFirstResponse 记录技术支持在工单话题内的首次响应时间。
+func (h *wrappedMessageHandler) recordFirstResponse(ctx context.Context, t *models.Ticket) {
+	if !t.FirstResponseAt.IsZero() {
+		return
+	}
+	t.FirstResponseAt = time.Now()
+	if err := h.tickets.Save(ctx, t); err != nil {
+		log.Printf("[SLA] Failed to record first response of ticket %s: %v", t.ID, err)
+	}
+}
+
+// buildSLAReport 构建工单的 SLA 状态和累计超时计数（话题内 sla 命令）。
+func (h *wrappedMessageHandler) buildSLAReport(ctx context.Context, t *models.Ticket) string {
+	var sb strings.Builder
+	loc := h.cfg.Bot.Location()
+
+	priority := h.slaPriority(t)
+	target, ok := h.cfg.SLA.Target(t.Priority)
+	sb.WriteString(fmt.Sprintf("⏱ %s SLA（%s）\n", t.ID, priority))
+	if !ok {
+		sb.WriteString("该优先级未配置 SLA。/ No SLA configured for this priority.\n")
+	} else {
+		writeSLALine(&sb, t, models.SLAFirstResponse, target.FirstResponseDuration(), t.FirstResponseAt, loc)
+		writeSLALine(&sb, t, models.SLAResolution, target.ResolutionDuration(), t.ResolvedAt, loc)
+	}
+
+	breaches, err := h.tickets.SLABreaches(ctx)
+	if err != nil {
+		log.Printf("[SLA] %v", err)
+		return sb.String()
+	}
+	sort.Slice(breaches, func(i, j int) bool {
+		if breaches[i].Priority != breaches[j].Priority {
+			return breaches[i].Priority < breaches[j].Priority
+		}
+		return breaches[i].Kind < breaches[j].Kind
+	})
+	sb.WriteString("\n📊 累计超时 / Breaches:")
+	if len(breaches) == 0 {
+		sb.WriteString(" 0")
+	}
+	for _, b := range breaches {
+		sb.WriteString(fmt.Sprintf("\n  %s %s: %d", b.Priority, slaKindLabels[b.Kind], b.Count))
+	}
+	return sb.String()
+}
+
+// writeSLALine 写入单个考核项的截止时间和完成情况。
+func writeSLALine(sb *strings.Builder, t *models.Ticket, kind string, limit time.Duration, done time.Time, loc *time.Location) {
+	label := slaKindLabels[kind]
+	if limit <= 0 {
+		sb.WriteString(fmt.Sprintf("%s: 不考核 / Not tracked\n", label))
+		return
+	}
+	deadline := t.SLAClockStart(kind).Add(limit).In(loc).Format("01-02 15:04")
+	status := "进行中 / Pending"
+	switch {
+	case !done.IsZero():
+		status = "已完成 / Done " + done.In(loc).Format("01-02 15:04")
+	case t.SLABreachedFor(kind):
+		status = "已超时 / Breached"
+	}
+	sb.WriteString(fmt.Sprintf("%s: 截止 %s ｜ %s\n", label, deadline, status))
+}
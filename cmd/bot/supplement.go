@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/even/feishu-bot/internal/conversation"
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// supplementFileWords 是补充请求中表示日志 / 录屏等附件的词（忽略大小写）。
+var supplementFileWords = []string{
+	"日志", "附件", "文件", "录屏", "视频", "截图", "log", "logs", "file", "files", "recording", "video", "screenshot",
+}
+
+// parseSupplementRequest 将命令参数解析为请求补充的字段和附件，返回无法识别的参数。
+func parseSupplementRequest(args []string) (fields []string, files bool, unknown []string) {
+	for _, arg := range args {
+		arg = strings.Trim(arg, ",，、")
+		if arg == "" {
+			continue
+		}
+		if isSupplementFileWord(arg) {
+			files = true
+			continue
+		}
+		field, ok := findFieldByAlias(arg)
+		if !ok {
+			unknown = append(unknown, arg)
+			continue
+		}
+		if !containsString(fields, field.Key) {
+			fields = append(fields, field.Key)
+		}
+	}
+	return fields, files, unknown
+}
+
+// isSupplementFileWord 判断参数是否表示附件。
+func isSupplementFileWord(arg string) bool {
+	lower := strings.ToLower(arg)
+	for _, w := range supplementFileWords {
+		if lower == w {
+			return true
+		}
+	}
+	return false
+}
+
+// findFieldByAlias 按字段名称（key、短名、显示名、别名）精确查找字段（忽略大小写）。
+func findFieldByAlias(name string) (models.FieldDef, bool) {
+	for _, f := range models.AllFields() {
+		for _, alias := range f.FieldAliases() {
+			if alias != "" && strings.EqualFold(alias, name) {
+				return f, true
+			}
+		}
+	}
+	return models.FieldDef{}, false
+}
+
+// containsString 判断 list 中是否包含 s。
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// requestSupplement 处理话题内的「need 字段…」命令：在用户私聊中开始补充信息会话。
+func (h *wrappedMessageHandler) requestSupplement(ctx context.Context, msg feishu.ThreadMessage, t *models.Ticket, args []string, reply func(string) error) error {
+	if t.Mode == models.ModeSuggestion {
+		return reply("建议反馈不支持补充信息。/ Suggestions do not support info requests.")
+	}
+	fields, files, unknown := parseSupplementRequest(args)
+	if len(unknown) > 0 || (len(fields) == 0 && !files) {
+		return reply(fmt.Sprintf("无法识别的字段：%s\n请使用字段名称（如「戒指SN」「应用版本」）或「日志」。/ Please name the fields or \"logs\".", strings.Join(unknown, "、")))
+	}
+
+	req := models.SupplementRequest{TicketID: t.ID, Fields: fields, Files: files, RequestedBy: msg.SenderID}
+	var conv *models.Conversation
+	var startErr error
+	h.runLocked(t.ChatID, func(ctx context.Context) {
+		conv, startErr = h.conversationManager.StartSupplement(ctx, t, req)
+	})
+	if errors.Is(startErr, conversation.ErrDraftInProgress) {
+		return reply("用户正在填写新的反馈，请稍后再试或直接在话题中沟通。/ The user is filling in another report, please try again later.")
+	}
+	if startErr != nil {
+		log.Printf("[Supplement] Failed to start supplement for %s: %v", t.ID, startErr)
+		_ = reply("抱歉，发起补充信息失败，请稍后重试。/ Failed to request more info, please try again later.")
+		return startErr
+	}
+
+	missing := conv.GetMissingFields()
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🔎 技术支持需要您补充以下信息（工单 %s）/ Support needs more info for ticket %s:\n", t.ID, t.ID))
+	for _, name := range missing {
+		sb.WriteString(fmt.Sprintf("  - %s\n", name))
+	}
+	sb.WriteString("\n请直接回复或发送附件。/ Please reply here or send the files.")
+	if err := h.feishuClient.SendTextMessage(ctx, t.ChatID, sb.String()); err != nil {
+		log.Printf("[Supplement] Failed to notify user of %s: %v", t.ID, err)
+	}
+
+	log.Printf("[Supplement] %s requested %v (files=%v) for ticket %s", msg.SenderID, fields, files, t.ID)
+	return reply(fmt.Sprintf("✅ 已请用户补充 / Requested from user: %s", strings.Join(missing, "、")))
+}
+
+// submitSupplement 将补充信息会话的内容追加到原工单话题，并合并到工单记录。
+func (h *wrappedMessageHandler) submitSupplement(ctx context.Context, conv *models.Conversation) error {
+	chatID := conv.ChatID
+	t, err := h.tickets.Get(ctx, conv.Supplement.TicketID)
+	if err != nil {
+		log.Printf("[Supplement] Failed to get ticket %s: %v", conv.Supplement.TicketID, err)
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, "提交失败，请稍后重试。\nSubmission failed. Please try again later.")
+		return err
+	}
+	if t == nil {
+		log.Printf("[Supplement] Ticket %s no longer exists, clearing chat %s", conv.Supplement.TicketID, chatID)
+		_ = h.conversationManager.ClearConversation(ctx, chatID)
+		return h.feishuClient.SendTextMessage(ctx, chatID, "对应的工单已不存在，补充信息未提交。\nThe ticket no longer exists, your info was not submitted.")
+	}
+
+	if err := h.escalationHandler.AppendSupplement(ctx, t, conv); err != nil {
+		_ = h.feishuClient.SendTextMessage(ctx, chatID, "提交失败，请稍后重试。\nSubmission failed. Please try again later.")
+		h.updateConfirmCard(ctx, conv, feishu.BuildConfirmCard(conv.GetUserSummary(), 0))
+		return err
+	}
+	h.updateConfirmCard(ctx, conv, feishu.BuildConfirmResultCard("✅ 已补充 / Added · "+t.ID, feishu.CardTemplateGreen, conv.GetSupplementSummary()))
+
+	// 工单记录由话题命令在话题锁内修改；这里持有会话锁（锁顺序为话题 → 会话），因此在话题锁内异步合并
+	ticketID := t.ID
+	go h.runLocked(t.RootMsgID, func(ctx context.Context) {
+		h.applySupplement(ctx, ticketID, conv)
+	})
+
+	_ = h.conversationManager.ClearConversation(ctx, chatID)
+	log.Printf("[Supplement] Supplement appended to ticket %s from chat %s", t.ID, chatID)
+	return h.feishuClient.SendTextMessage(ctx, chatID, fmt.Sprintf("✅ 补充信息已添加到工单 %s，谢谢！\nYour info has been added to ticket %s. Thank you!", t.ID, t.ID))
+}
+
+// applySupplement 在话题锁内重新读取工单，合并补充信息后保存并更新话题摘要。
+func (h *wrappedMessageHandler) applySupplement(ctx context.Context, ticketID string, conv *models.Conversation) {
+	t, err := h.tickets.Get(ctx, ticketID)
+	if err != nil || t == nil {
+		log.Printf("[Supplement] Failed to reload ticket %s: %v", ticketID, err)
+		return
+	}
+	t.ApplySupplement(conv)
+	if err := h.tickets.Save(ctx, t); err != nil {
+		log.Printf("[Supplement] Failed to save ticket %s: %v", t.ID, err)
+		return
+	}
+	_ = h.escalationHandler.UpdateTicketMessage(ctx, t)
+}
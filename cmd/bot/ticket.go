@@ -0,0 +1,157 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/even/feishu-bot/internal/ticket"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// ticketQueryPrefixes 是查询工单命令的前缀，后面跟工单号或 SN。
+var ticketQueryPrefixes = []string{"查询工单", "查工单", "ticket ", "find ticket "}
+
+// ticketQueryLimit 是按 SN 查询时最多展示的工单数。
+const ticketQueryLimit = 5
+
+// myTicketsCommands 是查看自己工单列表的命令（精确匹配，忽略大小写）。
+var myTicketsCommands = []string{"我的工单", "工单状态", "查看工单", "my tickets", "my ticket", "ticket status"}
+
+// myTicketsLimit 是「我的工单」最多展示的工单数。
+const myTicketsLimit = 10
+
+// myTicketsTitleLength 是工单列表中简短标题的最大字符数。
+const myTicketsTitleLength = 20
+
+// botMenuMyTickets 是机器人自定义菜单「我的工单」的 event_key（需在开发者后台配置相同的值）。
+const botMenuMyTickets = "my_tickets"
+
+// isMyTicketsCommand 判断消息是否为查看自己工单列表的命令。
+func isMyTicketsCommand(content string) bool {
+	lower := strings.ToLower(strings.TrimSpace(content))
+	for _, c := range myTicketsCommands {
+		if lower == c {
+			return true
+		}
+	}
+	return false
+}
+
+// handleMyTickets 回复用户最近提交的工单列表。
+func (h *wrappedMessageHandler) handleMyTickets(ctx context.Context, chatID, senderID string) error {
+	tickets, err := h.tickets.ListByUser(ctx, senderID, myTicketsLimit)
+	if err != nil {
+		return h.replyTicketError(ctx, chatID, err)
+	}
+	return h.feishuClient.SendTextMessage(ctx, chatID, buildMyTicketsReply(tickets))
+}
+
+// HandleBotMenu 处理机器人自定义菜单：「我的工单」按用户 open_id 回复工单列表。
+func (h *wrappedMessageHandler) HandleBotMenu(ctx context.Context, openID, eventKey string) error {
+	if eventKey != botMenuMyTickets {
+		log.Printf("[Ticket] Ignoring unknown bot menu event_key %q", eventKey)
+		return nil
+	}
+
+	tickets, err := h.tickets.ListByUser(ctx, openID, myTicketsLimit)
+	if err != nil {
+		log.Printf("[Ticket] Query failed: %v", err)
+		_ = h.feishuClient.SendTextMessageToUser(ctx, openID, "抱歉，查询工单时出错了，请稍后重试。\nSorry, failed to look up the ticket. Please try again later.")
+		return err
+	}
+	return h.feishuClient.SendTextMessageToUser(ctx, openID, buildMyTicketsReply(tickets))
+}
+
+// parseTicketQuery 解析「查询工单 <工单号|SN>」/ "ticket <id|sn>"，返回查询内容。
+// 查询内容必须是单个词，避免把 "ticket system is down" 这类问题描述当作命令。
+func parseTicketQuery(content string) (string, bool) {
+	text := strings.TrimSpace(content)
+	lower := strings.ToLower(text)
+	for _, p := range ticketQueryPrefixes {
+		if !strings.HasPrefix(lower, p) {
+			continue
+		}
+		query := strings.TrimSpace(text[len(p):])
+		if query != "" && len(strings.Fields(query)) == 1 {
+			return query, true
+		}
+	}
+	return "", false
+}
+
+// handleTicketQuery 按工单号或 SN 查询用户自己的工单。
+func (h *wrappedMessageHandler) handleTicketQuery(ctx context.Context, chatID, senderID, query string) error {
+	var found []*models.Ticket
+
+	if id, ok := ticket.ParseID(query); ok {
+		t, err := h.tickets.Get(ctx, id)
+		if err != nil {
+			return h.replyTicketError(ctx, chatID, err)
+		}
+		if t != nil && t.UserID == senderID {
+			found = append(found, t)
+		}
+	}
+
+	if len(found) == 0 {
+		tickets, err := h.tickets.ListBySN(ctx, query, 0)
+		if err != nil {
+			return h.replyTicketError(ctx, chatID, err)
+		}
+		for _, t := range tickets {
+			if t.UserID == senderID && len(found) < ticketQueryLimit {
+				found = append(found, t)
+			}
+		}
+	}
+
+	if len(found) == 0 {
+		return h.feishuClient.SendTextMessage(ctx, chatID, fmt.Sprintf("没有找到与「%s」相关的工单。\nNo ticket found for \"%s\".", query, query))
+	}
+
+	replies := make([]string, 0, len(found))
+	for _, t := range found {
+		replies = append(replies, buildTicketReply(t))
+	}
+	return h.feishuClient.SendTextMessage(ctx, chatID, strings.Join(replies, "\n\n"))
+}
+
+// replyTicketError 记录工单查询失败并提示用户。
+func (h *wrappedMessageHandler) replyTicketError(ctx context.Context, chatID string, err error) error {
+	log.Printf("[Ticket] Query failed: %v", err)
+	_ = h.feishuClient.SendTextMessage(ctx, chatID, "抱歉，查询工单时出错了，请稍后重试。\nSorry, failed to look up the ticket. Please try again later.")
+	return err
+}
+
+// buildMyTicketsReply 构建工单列表：每个工单一行工单号、简短标题、提交时间、状态和负责人。
+func buildMyTicketsReply(tickets []*models.Ticket) string {
+	if len(tickets) == 0 {
+		return "您还没有提交过工单。直接描述遇到的问题即可开始反馈。\nYou have not submitted any tickets yet. Just describe your issue to get started."
+	}
+
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🎫 您最近的工单 / Your recent tickets（%d）\n", len(tickets)))
+	for _, t := range tickets {
+		assignee := "待分配 / Unassigned"
+		if t.Assignee != "" {
+			assignee = atText(t.Assignee)
+		}
+		sb.WriteString(fmt.Sprintf("\n%s ｜ %s\n", t.ID, t.ShortTitle(myTicketsTitleLength)))
+		sb.WriteString(fmt.Sprintf("  %s ｜ %s ｜ 负责人 / Assignee: %s\n",
+			t.CreatedAt.Format("2006-01-02 15:04"), t.StatusLabel(), assignee))
+	}
+	sb.WriteString("\n发送「查询工单 工单号」查看详情。/ Send \"ticket <id>\" for details.")
+	return sb.String()
+}
+
+// buildTicketReply 构建工单详情：工单号、状态、提交时间和提交内容摘要。
+func buildTicketReply(t *models.Ticket) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("🎫 工单 / Ticket %s\n", t.ID))
+	sb.WriteString(fmt.Sprintf("状态 / Status: %s\n", t.StatusLabel()))
+	sb.WriteString(fmt.Sprintf("提交时间 / Submitted: %s\n\n", t.CreatedAt.Format("2006-01-02 15:04")))
+	sb.WriteString(t.Draft().GetUserSummary())
+	return strings.TrimRight(sb.String(), "\n")
+}
@@ -0,0 +1,140 @@
+// Package assign 提供新工单的自动分配：从路由的分配池中选择未解决工单最少的技术支持
+// （相同时按轮询顺序），跳过离开和已达上限的人。
+package assign
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/even/feishu-bot/internal/config"
+	"github.com/even/feishu-bot/internal/ticket"
+)
+
+// DefaultPool 是默认群组（没有命中路由）和没有单独分配池的路由使用的分配池名称。
+const DefaultPool = config.DefaultRouteName
+
+// Assigner 是自动分配器。
+type Assigner struct {
+	pools    map[string][]string // 小写路由名称 → 分配池
+	maxOpen  int
+	capacity map[string]int // open_id → 单独设置的上限
+	tickets  *ticket.Store
+}
+
+// New 根据配置创建分配器。
+func New(cfg config.AssignmentConfig, tickets *ticket.Store) (*Assigner, error) {
+	if cfg.MaxOpen < 0 {
+		return nil, fmt.Errorf("assignment.max_open must not be negative")
+	}
+	a := &Assigner{
+		pools:    make(map[string][]string),
+		maxOpen:  cfg.MaxOpen,
+		capacity: make(map[string]int),
+		tickets:  tickets,
+	}
+	for route, users := range cfg.Pools {
+		var ids []string
+		for _, id := range users {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 {
+			a.pools[strings.ToLower(strings.TrimSpace(route))] = ids
+		}
+	}
+	for i, c := range cfg.Capacity {
+		user := strings.TrimSpace(c.User)
+		if user == "" {
+			return nil, fmt.Errorf("assignment.capacity[%d].user is required", i)
+		}
+		if c.MaxOpen < 0 {
+			return nil, fmt.Errorf("assignment.capacity[%d].max_open must not be negative", i)
+		}
+		a.capacity[user] = c.MaxOpen
+	}
+	return a, nil
+}
+
+// Enabled 判断是否配置了分配池。
+func (a *Assigner) Enabled() bool {
+	return len(a.pools) > 0
+}
+
+// Pick 为路由的新工单选择负责人：分配池中未解决工单最少的人，相同时从轮询位置起第一个；
+// 离开或已达上限的人跳过。没有可分配的人时返回空。
+func (a *Assigner) Pick(ctx context.Context, route string) (string, error) {
+	name, pool := a.pool(route)
+	if len(pool) == 0 {
+		return "", nil
+	}
+
+	away, err := a.tickets.AwayUsers(ctx)
+	if err != nil {
+		return "", err
+	}
+	load, err := a.tickets.OpenAssignments(ctx)
+	if err != nil {
+		return "", err
+	}
+	rr, err := a.tickets.NextRoundRobin(ctx, name)
+	if err != nil {
+		return "", err
+	}
+
+	best := a.choose(pool, rr, away, load)
+	if best == "" {
+		log.Printf("[Assign] No available engineer in pool %q (%d members)", name, len(pool))
+	}
+	return best, nil
+}
+
+// choose 从轮询位置 rr 起依次查看分配池，返回未解决工单最少的人（相同时取先看到的）；
+// 离开或已达上限的人跳过，都不可分配时返回空。
+func (a *Assigner) choose(pool []string, rr int64, away map[string]bool, load map[string]int) string {
+	best, bestLoad := "", 0
+	for i := range pool {
+		user := pool[(int(rr%int64(len(pool)))+i)%len(pool)]
+		if away[user] {
+			continue
+		}
+		if limit := a.limit(user); limit > 0 && load[user] >= limit {
+			continue
+		}
+		if best == "" || load[user] < bestLoad {
+			best, bestLoad = user, load[user]
+		}
+	}
+	return best
+}
+
+// InPool 判断用户是否在任一分配池中。
+func (a *Assigner) InPool(user string) bool {
+	for _, pool := range a.pools {
+		for _, id := range pool {
+			if id == user {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// pool 返回路由使用的分配池名称和成员（没有单独分配池时使用 default）。
+func (a *Assigner) pool(route string) (string, []string) {
+	name := strings.ToLower(route)
+	if users := a.pools[name]; len(users) > 0 {
+		return name, users
+	}
+	return DefaultPool, a.pools[DefaultPool]
+}
+
+// limit 返回用户同时负责的未解决工单上限，0 表示不限制。
+func (a *Assigner) limit(user string) int {
+	if n, ok := a.capacity[user]; ok {
+		return n
+	}
+	return a.maxOpen
+}
@@ -0,0 +1,86 @@
+package assign
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/even/feishu-bot/internal/config"
+)
+
+func newTestAssigner(t *testing.T) *Assigner {
+	t.Helper()
+	a, err := New(config.AssignmentConfig{
+		Pools: map[string][]string{
+			"Default": {"ou_a", "ou_b", "ou_c"},
+			"ring":    {"ou_r1", " ", "ou_r2"},
+		},
+		MaxOpen: 3,
+		Capacity: []config.EngineerCapacity{
+			{User: "ou_b", MaxOpen: 0},
+			{User: "ou_c", MaxOpen: 5},
+		},
+	}, nil)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return a
+}
+
+func TestAssignerChoose(t *testing.T) {
+	a := newTestAssigner(t)
+	pool := []string{"ou_a", "ou_b", "ou_c"}
+
+	tests := []struct {
+		name string
+		rr   int64
+		away []string
+		load map[string]int
+		want string
+	}{
+		{name: "round robin start", rr: 0, want: "ou_a"},
+		{name: "round robin next", rr: 1, want: "ou_b"},
+		{name: "round robin wraps", rr: 5, want: "ou_c"},
+		{name: "fewest open wins", rr: 0, load: map[string]int{"ou_a": 2, "ou_b": 1, "ou_c": 1}, want: "ou_b"},
+		{name: "tie broken from round robin position", rr: 2, load: map[string]int{"ou_a": 1, "ou_b": 1, "ou_c": 1}, want: "ou_c"},
+		{name: "away skipped", rr: 0, away: []string{"ou_b"}, load: map[string]int{"ou_a": 1, "ou_c": 2}, want: "ou_a"},
+		{name: "max_open skipped", rr: 0, load: map[string]int{"ou_a": 3, "ou_b": 4, "ou_c": 5}, want: "ou_b"},
+		{name: "capacity overrides max_open", rr: 0, away: []string{"ou_b"}, load: map[string]int{"ou_a": 3, "ou_c": 4}, want: "ou_c"},
+		{name: "nobody available", rr: 0, away: []string{"ou_a", "ou_b", "ou_c"}, want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			away := make(map[string]bool)
+			for _, u := range tt.away {
+				away[u] = true
+			}
+			if got := a.choose(pool, tt.rr, away, tt.load); got != tt.want {
+				t.Errorf("choose(rr=%d, away=%v, load=%v) = %q, want %q", tt.rr, tt.away, tt.load, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAssignerPool(t *testing.T) {
+	a := newTestAssigner(t)
+
+	tests := []struct {
+		route string
+		name  string
+		users []string
+	}{
+		{route: "ring", name: "ring", users: []string{"ou_r1", "ou_r2"}},
+		{route: "RING", name: "ring", users: []string{"ou_r1", "ou_r2"}},
+		{route: "glasses", name: DefaultPool, users: []string{"ou_a", "ou_b", "ou_c"}},
+		{route: "", name: DefaultPool, users: []string{"ou_a", "ou_b", "ou_c"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.route, func(t *testing.T) {
+			name, users := a.pool(tt.route)
+			if name != tt.name || !reflect.DeepEqual(users, tt.users) {
+				t.Errorf("pool(%q) = %q, %v, want %q, %v", tt.route, name, users, tt.name, tt.users)
+			}
+		})
+	}
+}
@@ -4,8 +4,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"regexp"
 	"strings"
+	"time"
 
+	"github.com/even/feishu-bot/pkg/models"
 	"github.com/spf13/viper"
 )
 
@@ -15,6 +18,20 @@ type Config struct {
 	LLM    LLMConfig    `mapstructure:"llm"`
 	Redis  RedisConfig  `mapstructure:"redis"`
 	Bot    BotConfig    `mapstructure:"bot"`
+	SLA    SLAConfig    `mapstructure:"sla"`
+	OnCall OnCallConfig `mapstructure:"oncall"`
+	// Assignment auto-assigns new tickets to engineers from a pool.
+	Assignment AssignmentConfig `mapstructure:"assignment"`
+	// BusinessHours is the support team's working calendar (off-hours notices and held escalations).
+	BusinessHours BusinessHoursConfig `mapstructure:"business_hours"`
+	// Severity classifies each escalated issue before it is posted to the group.
+	Severity SeverityConfig `mapstructure:"severity"`
+	// Fields is the information schema collected from users; the LLM prompt,
+	// extraction result, welcome message and summaries are all generated from it.
+	Fields []models.FieldDef `mapstructure:"fields"`
+	// Routes picks the escalation group (and title / mentions) per submission;
+	// the first matching route wins, otherwise feishu.escalation_group_id is used.
+	Routes []models.EscalationRoute `mapstructure:"routes"`
 }
 
 // FeishuConfig holds Feishu (Lark) specific configuration.
@@ -31,6 +48,8 @@ type LLMConfig struct {
 	BaseURL    string `mapstructure:"base_url"`
 	Model      string `mapstructure:"model"`
 	MaxHistory int    `mapstructure:"max_history"`
+	// ConfidenceThreshold is the minimum extraction confidence accepted without asking the user to confirm.
+	ConfidenceThreshold float64 `mapstructure:"confidence_threshold"`
 }
 
 // RedisConfig holds Redis connection configuration.
@@ -39,13 +58,224 @@ type RedisConfig struct {
 	Password   string `mapstructure:"password"`
 	DB         int    `mapstructure:"db"`
 	Expiration int    `mapstructure:"expiration"`
+	// ArchiveRetention is how long (seconds) cleared or expired drafts stay restorable, 0 uses the default.
+	ArchiveRetention int `mapstructure:"archive_retention"`
 }
 
 // BotConfig holds bot-specific configuration.
 type BotConfig struct {
 	EscalationKeywords   []string `mapstructure:"escalation_keywords"`
 	ClearContextKeywords []string `mapstructure:"clear_context_keywords"`
-	SessionTimeout       int      `mapstructure:"session_timeout"`
+	SessionTimeout       int      `mapstructure:"session_timeout"`       // idle minutes before a draft expires, 0 expires it with redis.expiration
+	SessionReminder      int      `mapstructure:"session_reminder"`      // minutes before expiry to remind the user, 0 disables
+	SessionExpiryAction  string   `mapstructure:"session_expiry_action"` // what to do on expiry: notify / submit
+	Timezone             string   `mapstructure:"timezone"`              // users' IANA time zone, used to resolve occurrence times
+	ConfirmTimeout       int      `mapstructure:"confirm_timeout"`       // minutes before an unanswered confirmation card is auto-submitted, 0 disables
+	FormCard             string   `mapstructure:"form_card"`             // form card mode: auto / command / off
+	RelayWindow          int      `mapstructure:"relay_window"`          // minutes after a support reply that user messages are relayed to the ticket thread, 0 disables
+	FollowUpWindow       int      `mapstructure:"follow_up_window"`      // minutes after submission that user messages are appended to the ticket thread, 0 disables
+	ResolveReactions     []string `mapstructure:"resolve_reactions"`     // reactions (emoji_type) on the ticket root message that mark it resolved
+}
+
+// SLAConfig holds per-priority ticket SLA targets and reminder settings.
+type SLAConfig struct {
+	DefaultPriority string               `mapstructure:"default_priority"` // priority used for tickets without one
+	ReminderBefore  int                  `mapstructure:"reminder_before"`  // minutes before a breach to remind, 0 reminds only on breach
+	OnCall          []string             `mapstructure:"oncall"`           // open_ids reminded when the ticket has no assignee
+	Targets         map[string]SLATarget `mapstructure:"targets"`          // priority (P0~P3) → SLA target
+}
+
+// SLATarget is the SLA target of one priority.
+type SLATarget struct {
+	FirstResponse int `mapstructure:"first_response"` // first response limit (minutes), 0 if not tracked
+	Resolution    int `mapstructure:"resolution"`     // resolution limit (minutes), 0 if not tracked
+}
+
+// OnCallConfig holds the weekly on-call rotation per route and temporary overrides.
+// When File is set the schedule is read from that YAML file instead (same keys), and
+// changes to the file take effect without a restart.
+type OnCallConfig struct {
+	File         string              `mapstructure:"file"`          // optional schedule file; the other keys here are ignored when set
+	Timezone     string              `mapstructure:"timezone"`      // IANA time zone of the handover time, defaults to bot.timezone
+	StartDate    string              `mapstructure:"start_date"`    // rotation start (YYYY-MM-DD): the first person takes over at that day's handover, rotating every 7 days
+	HandoverTime string              `mapstructure:"handover_time"` // handover time (HH:MM), defaults to 00:00
+	Rotations    map[string][]string `mapstructure:"rotations"`     // route name → weekly rotation of open_ids, default is the default group
+	Overrides    []OnCallOverride    `mapstructure:"overrides"`     // temporary overrides, taking precedence over the rotation
+}
+
+// AssignmentConfig holds automatic assignment of new tickets: each ticket goes to the
+// engineer in the route's pool with the fewest open assignments (round-robin on ties),
+// skipping engineers who are away or at capacity.
+type AssignmentConfig struct {
+	Pools    map[string][]string `mapstructure:"pools"`    // route name → pool of open_ids, default covers the default group and routes without a pool
+	MaxOpen  int                 `mapstructure:"max_open"` // open assignments per engineer, 0 for no limit
+	Capacity []EngineerCapacity  `mapstructure:"capacity"` // per-engineer limits overriding max_open
+}
+
+// EngineerCapacity overrides the open assignment limit of one engineer.
+type EngineerCapacity struct {
+	User    string `mapstructure:"user"`     // open_id
+	MaxOpen int    `mapstructure:"max_open"` // 0 for no limit
+}
+
+// BusinessHoursConfig holds the support team's working hours and holiday calendar.
+// Outside business hours users are told when to expect a first response, and
+// non-critical escalations can be held until the team comes online.
+type BusinessHoursConfig struct {
+	Timezone      string   `mapstructure:"timezone"`       // IANA time zone of business hours, defaults to bot.timezone
+	Weekdays      []string `mapstructure:"weekdays"`       // working days (mon ~ sun), defaults to Monday to Friday
+	Start         string   `mapstructure:"start"`          // opening time (HH:MM); business hours are off when start and end are both empty
+	End           string   `mapstructure:"end"`            // closing time (HH:MM)
+	Holidays      []string `mapstructure:"holidays"`       // holidays (YYYY-MM-DD), closed all day
+	Workdays      []string `mapstructure:"workdays"`       // make-up workdays (YYYY-MM-DD), taking precedence over weekdays and holidays
+	FirstResponse int      `mapstructure:"first_response"` // expected first response after opening (minutes), 0 to not mention it
+	Hold          bool     `mapstructure:"hold"`           // hold non-critical escalations off hours and post them at opening
+}
+
+// SeverityConfig holds the automatic severity classification of escalated issues:
+// the LLM classifies when enabled, falling back to keyword rules when it is not
+// configured or fails.
+type SeverityConfig struct {
+	LLM        bool                   `mapstructure:"llm"`        // classify with the LLM
+	Keywords   map[string][]string    `mapstructure:"keywords"`   // severity → keywords (matched from critical down to low)
+	Default    string                 `mapstructure:"default"`    // severity when no keyword matches, defaults to medium
+	Priorities map[string]string      `mapstructure:"priorities"` // severity → initial ticket priority (P0~P3, optional)
+	Critical   models.CriticalActions `mapstructure:"critical"`   // extra actions for critical issues
+}
+
+// OnCallOverride replaces the rotation for a route during a time window.
+type OnCallOverride struct {
+	Route string `mapstructure:"route"` // route name, empty for all routes
+	User  string `mapstructure:"user"`  // open_id of the stand-in
+	Start string `mapstructure:"start"` // start (YYYY-MM-DD HH:MM, handover time zone)
+	End   string `mapstructure:"end"`   // end (exclusive)
+}
+
+// defaultSLAPriority is used when sla.default_priority is not set.
+const defaultSLAPriority = "P2"
+
+// Enabled reports whether any SLA target is configured.
+func (s SLAConfig) Enabled() bool {
+	return len(s.Targets) > 0
+}
+
+// Target returns the SLA target of the priority, falling back to the default priority when it is empty.
+func (s SLAConfig) Target(priority string) (SLATarget, bool) {
+	if priority == "" {
+		priority = s.DefaultPriority
+	}
+	t, ok := s.Targets[priority]
+	return t, ok
+}
+
+// Enabled reports whether severity classification is configured.
+func (s SeverityConfig) Enabled() bool {
+	return s.LLM || len(s.Keywords) > 0
+}
+
+// ReminderBeforeDuration returns how long before a breach the reminder is sent.
+func (s SLAConfig) ReminderBeforeDuration() time.Duration {
+	return time.Duration(s.ReminderBefore) * time.Minute
+}
+
+// FirstResponseDuration returns the first-response time limit, 0 if not tracked.
+func (t SLATarget) FirstResponseDuration() time.Duration {
+	return time.Duration(t.FirstResponse) * time.Minute
+}
+
+// ResolutionDuration returns the resolution time limit, 0 if not tracked.
+func (t SLATarget) ResolutionDuration() time.Duration {
+	return time.Duration(t.Resolution) * time.Minute
+}
+
+// Draft expiry actions (bot.session_expiry_action).
+const (
+	SessionExpiryNotify = "notify" // tell the user the draft expired and clear it
+	SessionExpirySubmit = "submit" // submit the incomplete draft
+)
+
+// sessionExpiryGrace is how long (seconds) the conversation Redis TTL outlives
+// the draft expiry, so the expiry handler can still read and archive the draft.
+const sessionExpiryGrace = 600
+
+// SessionTimeoutDuration returns the idle time after which a draft expires.
+func (b BotConfig) SessionTimeoutDuration() time.Duration {
+	return time.Duration(b.SessionTimeout) * time.Minute
+}
+
+// SessionReminderDuration returns how long before expiry the user is reminded.
+func (b BotConfig) SessionReminderDuration() time.Duration {
+	return time.Duration(b.SessionReminder) * time.Minute
+}
+
+// DraftExpiryDuration returns the idle time after which a draft is expired and archived:
+// session_timeout when set, otherwise redis.expiration, so the Redis TTL never deletes a
+// draft that was not archived first. 0 means drafts never expire.
+func (c *Config) DraftExpiryDuration() time.Duration {
+	if c.Bot.SessionTimeout > 0 {
+		return c.Bot.SessionTimeoutDuration()
+	}
+	return time.Duration(c.Redis.Expiration) * time.Second
+}
+
+// ConversationTTL returns the Redis TTL of conversations in seconds, extended past the
+// draft expiry so the expiry handler can still read the draft.
+func (c *Config) ConversationTTL() int {
+	ttl := c.Redis.Expiration
+	if expiry := c.DraftExpiryDuration(); expiry > 0 {
+		if minTTL := int(expiry/time.Second) + sessionExpiryGrace; ttl < minTTL {
+			ttl = minTTL
+		}
+	}
+	return ttl
+}
+
+// DefaultRouteName names the default group (feishu.escalation_group_id) in on-call
+// rotations and assignment pools, so routes cannot use it.
+const DefaultRouteName = "default"
+
+// Form card modes (bot.form_card).
+const (
+	FormCardAuto    = "auto"    // send the form after the first message; the command also opens it
+	FormCardCommand = "command" // send the form only when the user asks for it
+	FormCardOff     = "off"     // never send the form
+)
+
+// RelayWindowDuration returns how long user messages are relayed to the ticket thread after a support reply.
+func (b BotConfig) RelayWindowDuration() time.Duration {
+	return time.Duration(b.RelayWindow) * time.Minute
+}
+
+// IsResolveReaction reports whether the emoji type marks a ticket as resolved.
+func (b BotConfig) IsResolveReaction(emojiType string) bool {
+	for _, r := range b.ResolveReactions {
+		if strings.EqualFold(strings.TrimSpace(r), emojiType) {
+			return true
+		}
+	}
+	return false
+}
+
+// FollowUpWindowDuration returns how long user messages are appended to the ticket thread after submission.
+func (b BotConfig) FollowUpWindowDuration() time.Duration {
+	return time.Duration(b.FollowUpWindow) * time.Minute
+}
+
+// ConfirmTimeoutDuration returns the auto-submit timeout of the confirmation card.
+func (b BotConfig) ConfirmTimeoutDuration() time.Duration {
+	return time.Duration(b.ConfirmTimeout) * time.Minute
+}
+
+// Location returns the configured user timezone, falling back to the local timezone.
+func (b BotConfig) Location() *time.Location {
+	if b.Timezone == "" {
+		return time.Local
+	}
+	loc, err := time.LoadLocation(b.Timezone)
+	if err != nil {
+		return time.Local
+	}
+	return loc
 }
 
 // Load loads the configuration from the specified config file.
@@ -84,6 +314,23 @@ func Load(configPath string) (*Config, error) {
 	cfg.LLM.BaseURL = strings.TrimSpace(cfg.LLM.BaseURL)
 	cfg.LLM.Model = strings.TrimSpace(cfg.LLM.Model)
 	cfg.LLM.Provider = strings.TrimSpace(cfg.LLM.Provider)
+	cfg.Bot.Timezone = strings.TrimSpace(cfg.Bot.Timezone)
+	cfg.Bot.FormCard = strings.ToLower(strings.TrimSpace(cfg.Bot.FormCard))
+	cfg.Bot.SessionExpiryAction = strings.ToLower(strings.TrimSpace(cfg.Bot.SessionExpiryAction))
+	if cfg.Bot.SessionExpiryAction == "" {
+		cfg.Bot.SessionExpiryAction = SessionExpiryNotify
+	}
+	if cfg.Bot.FormCard == "" {
+		cfg.Bot.FormCard = FormCardCommand
+	}
+	normalizeFields(cfg.Fields)
+	normalizeRoutes(cfg.Routes)
+	if err := normalizeSLA(&cfg.SLA); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
+	if err := normalizeSeverity(&cfg.Severity); err != nil {
+		return nil, fmt.Errorf("config validation failed: %w", err)
+	}
 
 	// Validate
 	if err := cfg.Validate(); err != nil {
@@ -93,6 +340,124 @@ func Load(configPath string) (*Config, error) {
 	return &cfg, nil
 }
 
+// normalizeFields trims field definitions and fills in defaults.
+func normalizeFields(fields []models.FieldDef) {
+	for i := range fields {
+		f := &fields[i]
+		f.Key = strings.TrimSpace(f.Key)
+		f.Name = strings.TrimSpace(f.Name)
+		f.ShortName = strings.TrimSpace(f.ShortName)
+		f.Description = strings.TrimSpace(f.Description)
+		f.Validate = strings.ToLower(strings.TrimSpace(f.Validate))
+		if f.ShortName == "" {
+			f.ShortName = f.Name
+		}
+		// Fields with required_if and no explicit required are required (only when the condition holds)
+		if f.IsConditional() && f.Required == nil {
+			required := true
+			f.Required = &required
+		}
+	}
+}
+
+// normalizeRoutes trims route definitions.
+func normalizeRoutes(routes []models.EscalationRoute) {
+	for i := range routes {
+		r := &routes[i]
+		r.Name = strings.TrimSpace(r.Name)
+		r.GroupID = strings.TrimSpace(r.GroupID)
+		r.Title = strings.TrimSpace(r.Title)
+		r.Mode = models.ConversationMode(strings.ToLower(strings.TrimSpace(string(r.Mode))))
+		mentions := r.Mentions[:0]
+		for _, id := range r.Mentions {
+			if id = strings.TrimSpace(id); id != "" {
+				mentions = append(mentions, id)
+			}
+		}
+		r.Mentions = mentions
+	}
+}
+
+// normalizeSLA normalizes priorities in the SLA config (viper lowercases map keys, so "p1" → "P1").
+func normalizeSLA(s *SLAConfig) error {
+	targets := make(map[string]SLATarget, len(s.Targets))
+	for key, target := range s.Targets {
+		priority, ok := models.NormalizePriority(key)
+		if !ok {
+			return fmt.Errorf("sla.targets: unknown priority %q", key)
+		}
+		targets[priority] = target
+	}
+	s.Targets = targets
+
+	if strings.TrimSpace(s.DefaultPriority) == "" {
+		s.DefaultPriority = defaultSLAPriority
+	}
+	priority, ok := models.NormalizePriority(s.DefaultPriority)
+	if !ok {
+		return fmt.Errorf("sla.default_priority %q is invalid", s.DefaultPriority)
+	}
+	s.DefaultPriority = priority
+
+	oncall := s.OnCall[:0]
+	for _, id := range s.OnCall {
+		if id = strings.TrimSpace(id); id != "" {
+			oncall = append(oncall, id)
+		}
+	}
+	s.OnCall = oncall
+	return nil
+}
+
+// normalizeSeverity normalizes severity levels and priorities in the severity config.
+func normalizeSeverity(s *SeverityConfig) error {
+	keywords := make(map[string][]string, len(s.Keywords))
+	for key, words := range s.Keywords {
+		severity, ok := models.ParseSeverity(key)
+		if !ok {
+			return fmt.Errorf("severity.keywords: unknown severity %q", key)
+		}
+		for _, w := range words {
+			if w = strings.TrimSpace(w); w != "" {
+				keywords[string(severity)] = append(keywords[string(severity)], w)
+			}
+		}
+	}
+	s.Keywords = keywords
+
+	priorities := make(map[string]string, len(s.Priorities))
+	for key, raw := range s.Priorities {
+		severity, ok := models.ParseSeverity(key)
+		if !ok {
+			return fmt.Errorf("severity.priorities: unknown severity %q", key)
+		}
+		priority, ok := models.NormalizePriority(raw)
+		if !ok {
+			return fmt.Errorf("severity.priorities.%s: unknown priority %q", key, raw)
+		}
+		priorities[string(severity)] = priority
+	}
+	s.Priorities = priorities
+
+	if strings.TrimSpace(s.Default) == "" {
+		s.Default = string(models.SeverityMedium)
+	}
+	severity, ok := models.ParseSeverity(s.Default)
+	if !ok {
+		return fmt.Errorf("severity.default %q is invalid", s.Default)
+	}
+	s.Default = string(severity)
+
+	mentions := s.Critical.Mentions[:0]
+	for _, id := range s.Critical.Mentions {
+		if id = strings.TrimSpace(id); id != "" {
+			mentions = append(mentions, id)
+		}
+	}
+	s.Critical.Mentions = mentions
+	return nil
+}
+
 // overrideFromEnv overrides config values from environment variables.
 func overrideFromEnv(cfg *Config) {
 	if addr := os.Getenv("REDIS_ADDR"); addr != "" {
@@ -140,6 +505,53 @@ func (c *Config) Validate() error {
 	if c.Feishu.EscalationGroupID == "" {
 		return fmt.Errorf("feishu.escalation_group_id is required")
 	}
+	if c.Bot.Timezone != "" {
+		if _, err := time.LoadLocation(c.Bot.Timezone); err != nil {
+			return fmt.Errorf("bot.timezone is invalid: %w", err)
+		}
+	}
+	if c.Bot.ConfirmTimeout < 0 {
+		return fmt.Errorf("bot.confirm_timeout must not be negative")
+	}
+	if c.Bot.RelayWindow < 0 || c.Bot.FollowUpWindow < 0 {
+		return fmt.Errorf("bot.relay_window and bot.follow_up_window must not be negative")
+	}
+	if c.Redis.ArchiveRetention < 0 {
+		return fmt.Errorf("redis.archive_retention must not be negative")
+	}
+	if c.Bot.SessionTimeout < 0 || c.Bot.SessionReminder < 0 {
+		return fmt.Errorf("bot.session_timeout and bot.session_reminder must not be negative")
+	}
+	if c.Bot.SessionTimeout > 0 && c.Bot.SessionReminder >= c.Bot.SessionTimeout {
+		return fmt.Errorf("bot.session_reminder must be shorter than bot.session_timeout")
+	}
+	switch c.Bot.SessionExpiryAction {
+	case SessionExpiryNotify, SessionExpirySubmit:
+	default:
+		return fmt.Errorf("bot.session_expiry_action must be one of notify, submit")
+	}
+	switch c.Bot.FormCard {
+	case FormCardAuto, FormCardCommand, FormCardOff:
+	default:
+		return fmt.Errorf("bot.form_card must be one of auto, command, off")
+	}
+	if c.SLA.ReminderBefore < 0 {
+		return fmt.Errorf("sla.reminder_before must not be negative")
+	}
+	for priority, target := range c.SLA.Targets {
+		if target.FirstResponse < 0 || target.Resolution < 0 {
+			return fmt.Errorf("sla.targets.%s must not be negative", priority)
+		}
+	}
+	if err := validateFields(c.Fields); err != nil {
+		return err
+	}
+	if err := validateRoutes(c.Routes, c.Fields); err != nil {
+		return err
+	}
+	if c.LLM.ConfidenceThreshold < 0 || c.LLM.ConfidenceThreshold > 1 {
+		return fmt.Errorf("llm.confidence_threshold must be between 0 and 1")
+	}
 	if c.LLM.APIKey != "" {
 		if c.LLM.BaseURL == "" {
 			return fmt.Errorf("llm.base_url is required when llm.api_key is provided")
@@ -151,6 +563,108 @@ func (c *Config) Validate() error {
 	return nil
 }
 
+// validateFields validates the field schema.
+func validateFields(fields []models.FieldDef) error {
+	if len(fields) == 0 {
+		return fmt.Errorf("fields must define at least one field")
+	}
+	seen := make(map[string]bool, len(fields))
+	for i, f := range fields {
+		if f.Key == "" {
+			return fmt.Errorf("fields[%d].key is required", i)
+		}
+		if seen[f.Key] {
+			return fmt.Errorf("fields[%d].key %q is duplicated", i, f.Key)
+		}
+		seen[f.Key] = true
+		if f.Name == "" {
+			return fmt.Errorf("fields[%d].name is required (key %q)", i, f.Key)
+		}
+		switch f.Validate {
+		case "", models.ValidateVersion, models.ValidateDatetime:
+		case models.ValidateSN:
+			if f.Pattern != "" {
+				if _, err := regexp.Compile(f.Pattern); err != nil {
+					return fmt.Errorf("fields[%d].pattern is invalid: %w", i, err)
+				}
+			}
+		case models.ValidateRegex:
+			if _, err := regexp.Compile(f.Pattern); err != nil || f.Pattern == "" {
+				return fmt.Errorf("fields[%d].pattern must be a valid regex for validate=regex (key %q)", i, f.Key)
+			}
+		case models.ValidateEnum:
+			if len(f.Enum) == 0 {
+				return fmt.Errorf("fields[%d].enum is required for validate=enum (key %q)", i, f.Key)
+			}
+		default:
+			return fmt.Errorf("fields[%d].validate %q is not supported (key %q)", i, f.Validate, f.Key)
+		}
+	}
+	for i, f := range fields {
+		if f.IsConditional() && !f.IsRequired() {
+			return fmt.Errorf("fields[%d].required must not be false when required_if is set (key %q)", i, f.Key)
+		}
+		for j, cond := range f.RequiredIf {
+			if !seen[cond.Field] {
+				return fmt.Errorf("fields[%d].required_if[%d].field %q is not a defined field", i, j, cond.Field)
+			}
+			if len(cond.Equals) == 0 && len(cond.Contains) == 0 && cond.Present == nil {
+				return fmt.Errorf("fields[%d].required_if[%d] needs at least one of equals / contains / present", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// validateRoutes validates the escalation routing table against the field schema.
+func validateRoutes(routes []models.EscalationRoute, fields []models.FieldDef) error {
+	defined := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		defined[f.Key] = true
+	}
+	names := make(map[string]bool, len(routes))
+	for i, r := range routes {
+		if r.Name == "" {
+			return fmt.Errorf("routes[%d].name is required", i)
+		}
+		if strings.EqualFold(r.Name, DefaultRouteName) {
+			return fmt.Errorf("routes[%d].name %q is reserved for the default group", i, r.Name)
+		}
+		if names[r.Name] {
+			return fmt.Errorf("routes[%d].name %q is duplicated", i, r.Name)
+		}
+		names[r.Name] = true
+		if r.GroupID == "" {
+			return fmt.Errorf("routes[%d].group_id is required (route %q)", i, r.Name)
+		}
+		switch r.Mode {
+		case models.ModeUnknown, models.ModeIssue, models.ModeSuggestion:
+		default:
+			return fmt.Errorf("routes[%d].mode must be one of issue, suggestion (route %q)", i, r.Name)
+		}
+		for j, cond := range r.Fields {
+			if !defined[cond.Field] {
+				return fmt.Errorf("routes[%d].fields[%d].field %q is not a defined field", i, j, cond.Field)
+			}
+			if len(cond.Equals) == 0 && len(cond.Contains) == 0 && cond.Present == nil {
+				return fmt.Errorf("routes[%d].fields[%d] needs at least one of equals / contains / present", i, j)
+			}
+		}
+		for j, v := range r.Versions {
+			if !defined[v.Field] {
+				return fmt.Errorf("routes[%d].versions[%d].field %q is not a defined field", i, j, v.Field)
+			}
+			if v.Min == "" && v.Max == "" {
+				return fmt.Errorf("routes[%d].versions[%d] needs min or max", i, j)
+			}
+			if (v.Min != "" && !models.IsValidVersion(v.Min)) || (v.Max != "" && !models.IsValidVersion(v.Max)) {
+				return fmt.Errorf("routes[%d].versions[%d] has an invalid version", i, j)
+			}
+		}
+	}
+	return nil
+}
+
 // IsEscalationKeyword checks if the given content contains an escalation keyword.
 func (c *Config) IsEscalationKeyword(content string) bool {
 	lowerContent := strings.ToLower(content)
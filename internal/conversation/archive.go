@@ -0,0 +1,74 @@
+// Package conversation 提供草稿归档与恢复功能。
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+var (
+	// ErrNoArchive 表示没有可恢复的归档草稿。
+	ErrNoArchive = errors.New("no archived draft")
+	// ErrNewerDraft 表示已开始了新的草稿，不能用归档覆盖。
+	ErrNewerDraft = errors.New("a newer draft exists")
+)
+
+// ArchiveConversation 归档并清除会话（用于清除上下文、取消提交、草稿过期），返回是否归档了草稿。
+// 没有内容的会话直接清除，不覆盖之前的归档。
+func (m *Manager) ArchiveConversation(ctx context.Context, chatID string) (bool, error) {
+	conv, err := m.store.GetConversation(ctx, chatID)
+	if err != nil {
+		return false, err
+	}
+
+	archived := false
+	if conv != nil && conv.HasDraft() {
+		if err := m.store.ArchiveConversation(ctx, conv); err != nil {
+			return false, err
+		}
+		archived = true
+		log.Printf("[Manager] Draft archived for chat %s", chatID)
+	}
+
+	return archived, m.store.ClearConversation(ctx, chatID)
+}
+
+// RestoreConversation 将最近归档的草稿恢复为当前会话，返回恢复后的会话。
+// 没有归档时返回 ErrNoArchive；当前已有新草稿时返回 ErrNewerDraft。
+func (m *Manager) RestoreConversation(ctx context.Context, chatID string) (*models.Conversation, error) {
+	archived, err := m.store.GetArchivedConversation(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if archived == nil {
+		return nil, ErrNoArchive
+	}
+
+	current, err := m.store.GetConversation(ctx, chatID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.HasDraft() {
+		return current, ErrNewerDraft
+	}
+
+	// 旧的确认卡片 / 表单卡片已失效，重新开始计时
+	archived.ConfirmCardID = ""
+	archived.ConfirmDeadline = time.Time{}
+	archived.FormCardID = ""
+	archived.UpdatedAt = time.Now()
+
+	if err := m.store.SaveConversation(ctx, archived); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	if err := m.store.DeleteArchivedConversation(ctx, chatID); err != nil {
+		log.Printf("[Manager] Failed to delete archive for chat %s: %v", chatID, err)
+	}
+	log.Printf("[Manager] Draft restored for chat %s", chatID)
+	return archived, nil
+}
@@ -0,0 +1,57 @@
+// Package conversation 提供提交确认（确认卡片 + 超时自动提交）的会话状态管理。
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// AwaitConfirmation 记录已发送的确认卡片；timeout 大于 0 时安排超时自动提交。
+func (m *Manager) AwaitConfirmation(ctx context.Context, chatID, cardMsgID string, timeout time.Duration) error {
+	conv, err := m.store.GetConversation(ctx, chatID)
+	if err != nil {
+		return err
+	}
+	if conv == nil {
+		return fmt.Errorf("conversation %s not found", chatID)
+	}
+
+	conv.ConfirmCardID = cardMsgID
+	conv.ConfirmDeadline = time.Time{}
+	if timeout > 0 {
+		conv.ConfirmDeadline = time.Now().Add(timeout)
+	}
+	if err := m.store.SaveConversation(ctx, conv); err != nil {
+		return fmt.Errorf("failed to save conversation: %w", err)
+	}
+
+	if conv.ConfirmDeadline.IsZero() {
+		return m.store.CancelConfirmDeadline(ctx, chatID)
+	}
+	log.Printf("[Manager] Awaiting confirmation for chat %s, auto-submit at %s", chatID, conv.ConfirmDeadline.Format(time.RFC3339))
+	return m.store.ScheduleConfirmDeadline(ctx, chatID, conv.ConfirmDeadline)
+}
+
+// CancelConfirmation 退出等待确认状态（用户选择继续编辑），返回更新后的会话；没有会话时返回 nil。
+func (m *Manager) CancelConfirmation(ctx context.Context, chatID string) (*models.Conversation, error) {
+	conv, err := m.store.GetConversation(ctx, chatID)
+	if err != nil || conv == nil {
+		return nil, err
+	}
+
+	conv.ConfirmCardID = ""
+	conv.ConfirmDeadline = time.Time{}
+	if err := m.store.SaveConversation(ctx, conv); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return conv, m.store.CancelConfirmDeadline(ctx, chatID)
+}
+
+// ClaimDueConfirmations 返回已到自动提交时间的会话 chatID（每个会话只会被认领一次）。
+func (m *Manager) ClaimDueConfirmations(ctx context.Context, now time.Time) ([]string, error) {
+	return m.store.ClaimDueConfirmDeadlines(ctx, now)
+}
@@ -0,0 +1,90 @@
+// Package conversation 提供草稿（已收集信息）的直接编辑功能。
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// ErrNoFiles 表示草稿中没有可删除的附件。
+var ErrNoFiles = errors.New("no attachments in draft")
+
+// SetField 直接设置字段值（绕过 LLM，但仍经过字段校验），返回更新后的会话。
+// 值未通过校验时返回 *ValidationError；发生时间不够具体时保留原文并返回 ErrAmbiguousTime。
+func (m *Manager) SetField(ctx context.Context, chatID, senderID string, field models.FieldDef, value string) (*models.Conversation, error) {
+	conv, err := m.store.GetOrCreateConversation(ctx, chatID, senderID, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	if conv.Mode == models.ModeUnknown {
+		conv.Mode = models.ModeIssue
+	}
+
+	normalized, verr := NormalizeFieldValue(field, value, time.Now().In(m.location))
+	var validationErr *ValidationError
+	if errors.As(verr, &validationErr) {
+		return conv, verr
+	}
+
+	conv.SetCollectedInfo(field.Key, value)
+	if errors.Is(verr, ErrAmbiguousTime) {
+		conv.MarkUnresolved(field.Key)
+	} else {
+		conv.SetNormalizedInfo(field.Key, normalized)
+	}
+	conv.DiscardPendingInfo(field.Key)
+	log.Printf("[Manager] Draft set %s = %q (normalized %q)", field.Key, value, normalized)
+
+	if err := m.store.SaveConversation(ctx, conv); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	if errors.Is(verr, ErrAmbiguousTime) {
+		return conv, verr
+	}
+	return conv, nil
+}
+
+// DeleteField 删除已收集的字段值（含标准值和待确认值），返回更新后的会话；没有会话时返回 nil。
+func (m *Manager) DeleteField(ctx context.Context, chatID string, field models.FieldDef) (*models.Conversation, error) {
+	conv, err := m.store.GetConversation(ctx, chatID)
+	if err != nil || conv == nil {
+		return nil, err
+	}
+
+	delete(conv.CollectedInfo, field.Key)
+	conv.SetNormalizedInfo(field.Key, "")
+	conv.DiscardPendingInfo(field.Key)
+	log.Printf("[Manager] Draft deleted %s", field.Key)
+
+	if err := m.store.SaveConversation(ctx, conv); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return conv, nil
+}
+
+// RemoveLastFile 删除最近上传的附件，返回更新后的会话和被删除的附件。
+// 没有会话或没有附件时返回 ErrNoFiles。
+func (m *Manager) RemoveLastFile(ctx context.Context, chatID string) (*models.Conversation, models.FileInfo, error) {
+	conv, err := m.store.GetConversation(ctx, chatID)
+	if err != nil {
+		return nil, models.FileInfo{}, err
+	}
+	if conv == nil || !conv.HasFiles() {
+		return conv, models.FileInfo{}, ErrNoFiles
+	}
+
+	removed := conv.Files[len(conv.Files)-1]
+	conv.Files = conv.Files[:len(conv.Files)-1]
+	conv.UpdatedAt = time.Now()
+	log.Printf("[Manager] Draft removed attachment %s", removed.FileName)
+
+	if err := m.store.SaveConversation(ctx, conv); err != nil {
+		return nil, models.FileInfo{}, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return conv, removed, nil
+}
@@ -0,0 +1,73 @@
+// Package conversation 提供表单卡片提交值的合并功能。
+package conversation
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/even/feishu-bot/internal/llm"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// ApplyFormValues 将表单卡片提交的字段值（字段 key → 值）合并到会话，返回回复内容。
+// 表单值视为用户明确填写（置信度 1），与 LLM 提取值走相同的校验与合并流程；空值表示不修改。
+// 返回值约定与 ProcessMessage 相同：信息完整时以 ConfirmPrefix 开头。
+func (m *Manager) ApplyFormValues(ctx context.Context, chatID, senderID string, values map[string]string) (string, error) {
+	conv, err := m.store.GetOrCreateConversation(ctx, chatID, senderID, "")
+	if err != nil {
+		return "", fmt.Errorf("failed to get conversation: %w", err)
+	}
+	conv.Mode = models.ModeIssue
+
+	result := &llm.ExtractionResult{Fields: make(map[string]llm.ExtractedValue)}
+	var lines []string
+	for _, field := range models.AllFields() {
+		value := strings.TrimSpace(values[field.Key])
+		if value == "" || isUnchangedFormValue(conv, field, value) {
+			continue
+		}
+		result.Fields[field.Key] = llm.ExtractedValue{Value: value, Confidence: 1}
+		lines = append(lines, fmt.Sprintf("%s: %s", field.ShortName, value))
+	}
+	log.Printf("[Manager] Form submitted in chat %s with %d fields", chatID, len(result.Fields))
+
+	// 记录为用户消息，同时作为解析相对时间（如"今天下午3点"）的参照
+	conv.AddMessage("user", "[表单 / Form]\n"+strings.Join(lines, "\n"))
+
+	outcome := m.mergeExtractedInfo(conv, result, m.getCollectedInfoSnapshot(conv))
+
+	if conv.IsInfoComplete() {
+		return m.buildConfirmResponse(ctx, conv)
+	}
+
+	response := m.buildSmartResponse(outcome, conv)
+	conv.AddMessage("assistant", response)
+	if err := m.store.SaveConversation(ctx, conv); err != nil {
+		return "", fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return response, nil
+}
+
+// MarkFormCardSent 记录已发送的表单卡片，避免重复主动发送。
+func (m *Manager) MarkFormCardSent(ctx context.Context, chatID, cardMsgID string) error {
+	conv, err := m.store.GetConversation(ctx, chatID)
+	if err != nil || conv == nil {
+		return err
+	}
+	conv.FormCardID = cardMsgID
+	if err := m.store.SaveConversation(ctx, conv); err != nil {
+		return fmt.Errorf("failed to save conversation: %w", err)
+	}
+	return nil
+}
+
+// isUnchangedFormValue 判断表单值是否为预填的原值（输入框预填原文，下拉框预填标准值），
+// 避免用下拉框的标准值覆盖带补充说明的原文（如 "yes, US node"）。
+func isUnchangedFormValue(conv *models.Conversation, field models.FieldDef, value string) bool {
+	if raw, ok := conv.GetCollectedInfo(field.Key); ok && raw == value {
+		return true
+	}
+	return field.Validate == models.ValidateEnum && conv.NormalizedInfo[field.Key] == value
+}
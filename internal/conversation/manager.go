@@ -3,9 +3,11 @@ package conversation
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"strings"
+	"time"
 
 	"github.com/even/feishu-bot/internal/llm"
 	"github.com/even/feishu-bot/pkg/models"
@@ -14,24 +16,52 @@ import (
 // EscalatePrefix 是触发自动转人工的响应前缀。
 const EscalatePrefix = "ESCALATE:"
 
+// ConfirmPrefix 是信息收集完毕、需要用户确认提交的响应前缀（后接草稿摘要）。
+const ConfirmPrefix = "CONFIRM:"
+
+// SupplementPrefix 是补充信息收集完毕、需要追加到原工单话题的响应前缀（后接补充内容摘要）。
+const SupplementPrefix = "SUPPLEMENT:"
+
+// DefaultConfidenceThreshold 是未配置时使用的置信度阈值。
+const DefaultConfidenceThreshold = 0.7
+
 // Manager 管理会话和信息收集。
 type Manager struct {
-	store   *Store
-	llm     llm.Client
-	prompts *PromptManager
+	store               *Store
+	llm                 llm.Client
+	prompts             *PromptManager
+	location            *time.Location
+	confidenceThreshold float64
+}
+
+// ManagerOptions 会话管理器的可选配置。
+type ManagerOptions struct {
+	Location            *time.Location // 用户时区，用于解析发生时间；nil 时使用本地时区
+	ConfidenceThreshold float64        // 低于此置信度的提取值需用户确认；0 时使用 DefaultConfidenceThreshold
 }
 
 // NewManager 创建新的会话管理器。
-func NewManager(store *Store, llmClient llm.Client, prompts *PromptManager) *Manager {
+func NewManager(store *Store, llmClient llm.Client, prompts *PromptManager, opts ManagerOptions) *Manager {
+	loc := opts.Location
+	if loc == nil {
+		loc = time.Local
+	}
+	threshold := opts.ConfidenceThreshold
+	if threshold <= 0 {
+		threshold = DefaultConfidenceThreshold
+	}
 	return &Manager{
-		store:   store,
-		llm:     llmClient,
-		prompts: prompts,
+		store:               store,
+		llm:                 llmClient,
+		prompts:             prompts,
+		location:            loc,
+		confidenceThreshold: threshold,
 	}
 }
 
 // ProcessMessage 处理用户消息，返回回复内容。
-// 如果返回值以 EscalatePrefix 开头，表示需要自动转人工。
+// 如果返回值以 EscalatePrefix 开头，表示需要自动转人工；
+// 以 ConfirmPrefix 开头表示信息已完整，需要用户确认后再提交。
 func (m *Manager) ProcessMessage(ctx context.Context, chatID, senderID, senderName, content, msgType, fileKey, messageID string) (string, error) {
 	log.Printf("[Manager] ProcessMessage: chatID=%s, content=%q, msgType=%s, fileKey=%s", chatID, content, msgType, fileKey)
 
@@ -69,6 +99,21 @@ func (m *Manager) ProcessMessage(ctx context.Context, chatID, senderID, senderNa
 	// 添加用户消息
 	conv.AddMessage("user", content)
 
+	// 有待确认的值时，先处理用户的确认/否认/指明字段
+	if len(conv.PendingInfo) > 0 {
+		if confirmed, handled := m.resolvePendingInfo(conv, trimmed); handled {
+			if conv.IsInfoComplete() {
+				return m.buildConfirmResponse(ctx, conv)
+			}
+			response := m.buildSmartResponse(mergeOutcome{newParts: confirmed}, conv)
+			conv.AddMessage("assistant", response)
+			if err := m.store.SaveConversation(ctx, conv); err != nil {
+				return "", fmt.Errorf("failed to save conversation: %w", err)
+			}
+			return response, nil
+		}
+	}
+
 	// 获取当前已收集的信息快照
 	collectedInfo := m.getCollectedInfoSnapshot(conv)
 
@@ -85,15 +130,15 @@ func (m *Manager) ProcessMessage(ctx context.Context, chatID, senderID, senderNa
 	}
 
 	// 合并新提取的信息到会话
-	newInfoParts := m.mergeExtractedInfo(conv, result, collectedInfo)
+	outcome := m.mergeExtractedInfo(conv, result, collectedInfo)
 
 	// 检查信息是否已完整
 	if conv.IsInfoComplete() {
-		return m.buildEscalateResponse(ctx, conv)
+		return m.buildConfirmResponse(ctx, conv)
 	}
 
 	// 构建智能回复
-	response := m.buildSmartResponse(newInfoParts, conv)
+	response := m.buildSmartResponse(outcome, conv)
 	conv.AddMessage("assistant", response)
 
 	// 保存会话
@@ -166,11 +211,7 @@ func (m *Manager) handleFileMessage(ctx context.Context, conv *models.Conversati
 
 	// 检查信息是否已完整
 	if conv.IsInfoComplete() {
-		conv.AddMessage("assistant", "收到文件。信息已完整，正在为您提交...\nFile received. All info collected, submitting...")
-		if err := m.store.SaveConversation(ctx, conv); err != nil {
-			return "", err
-		}
-		return m.buildEscalateResponse(ctx, conv)
+		return m.buildConfirmResponse(ctx, conv)
 	}
 
 	// 信息不完整，提示用户
@@ -204,14 +245,23 @@ func (m *Manager) getCollectedInfoSnapshot(conv *models.Conversation) map[string
 	return snapshot
 }
 
-// mergeExtractedInfo 将 LLM 提取的新信息合并到会话中，返回新收集/更新的信息描述。
-func (m *Manager) mergeExtractedInfo(conv *models.Conversation, result *llm.ExtractionResult, oldInfo map[string]string) []string {
-	var newParts []string
+// mergeOutcome 记录一次信息合并的结果。
+type mergeOutcome struct {
+	newParts       []string // 新收集/更新的信息描述
+	rejected       []string // 未通过校验、未写入 CollectedInfo 的信息描述
+	clarifications []string // 需要用户补充说明的问题（如发生时间不够具体）
+}
+
+// mergeExtractedInfo 将 LLM 提取的新信息校验后合并到会话中。
+func (m *Manager) mergeExtractedInfo(conv *models.Conversation, result *llm.ExtractionResult, oldInfo map[string]string) mergeOutcome {
+	var out mergeOutcome
 
-	fieldMap := result.ToFieldMap()
+	ref := m.messageTime(conv)
 
-	for _, key := range llm.AllFieldKeys {
-		newValue := fieldMap[key]
+	for _, field := range models.AllFields() {
+		key := field.Key
+		extracted := result.Fields[key]
+		newValue := extracted.Value
 		if newValue == "" {
 			continue // LLM 没有从当前消息中提取到此字段
 		}
@@ -219,41 +269,197 @@ func (m *Manager) mergeExtractedInfo(conv *models.Conversation, result *llm.Extr
 		if oldVal == newValue {
 			continue // 值没有变化，跳过
 		}
-		name := llm.FieldDisplayNames[key]
+		name := field.Name
+
+		normalized, err := NormalizeFieldValue(field, newValue, ref)
+		if errors.Is(err, ErrAmbiguousTime) && oldVal != "" && conv.NormalizedInfo[key] == "" {
+			// 用户在补充之前不明确的描述（如先说"昨天"，再说"下午3点"），合并后再解析
+			combined := oldVal + " " + newValue
+			if n, cerr := NormalizeFieldValue(field, combined, ref); cerr == nil {
+				newValue, normalized, err = combined, n, nil
+			}
+		}
+		if err != nil {
+			var verr *ValidationError
+			switch {
+			case errors.Is(err, ErrAmbiguousTime):
+				// 保留原文，请用户补充具体时间
+				conv.SetCollectedInfo(key, newValue)
+				conv.MarkUnresolved(key)
+				out.clarifications = append(out.clarifications, fmt.Sprintf("%s:「%s」不够具体，请补充日期和大概几点（如：昨天下午3点）/ please include the date and approximate time (e.g. yesterday 3pm)", name, newValue))
+				log.Printf("[Manager] Ambiguous %s = %q, asking for clarification", key, newValue)
+				continue
+			case errors.As(err, &verr):
+				out.rejected = append(out.rejected, fmt.Sprintf("%s:「%s」%s", name, newValue, verr.Reason))
+				log.Printf("[Manager] Rejected %s = %q: %s", key, newValue, verr.Reason)
+				continue
+			default:
+				log.Printf("[Manager] Failed to validate %s: %v", key, err)
+			}
+		}
+
+		// 置信度低的值先记为待确认，确认前不计入完整性判断
+		if extracted.Confidence < m.confidenceThreshold {
+			conv.SetPendingInfo(key, models.PendingValue{
+				Value:      newValue,
+				Normalized: normalized,
+				Confidence: extracted.Confidence,
+				Source:     extracted.Source,
+			})
+			log.Printf("[Manager] Pending %s = %q (confidence %.2f, source %q)", key, newValue, extracted.Confidence, extracted.Source)
+			continue
+		}
+
 		conv.SetCollectedInfo(key, newValue)
+		conv.SetNormalizedInfo(key, normalized)
+		conv.DiscardPendingInfo(key) // 明确给出的值覆盖之前待确认的值
+		display := newValue
+		if normalized != "" && normalized != newValue {
+			display = fmt.Sprintf("%s → %s", newValue, normalized)
+		}
 		if oldVal == "" {
-			newParts = append(newParts, fmt.Sprintf("%s: %s", name, newValue))
+			out.newParts = append(out.newParts, fmt.Sprintf("%s: %s", name, display))
 		} else {
-			newParts = append(newParts, fmt.Sprintf("%s: %s (updated)", name, newValue))
+			out.newParts = append(out.newParts, fmt.Sprintf("%s: %s (updated)", name, display))
 		}
-		log.Printf("[Manager] Collected %s = %q (was %q)", key, newValue, oldVal)
+		log.Printf("[Manager] Collected %s = %q (normalized %q, was %q)", key, newValue, normalized, oldVal)
 	}
 
-	return newParts
+	return out
 }
 
-// buildSmartResponse 根据新收集的信息和缺失信息构建回复。
-func (m *Manager) buildSmartResponse(newInfoParts []string, conv *models.Conversation) string {
-	var sb strings.Builder
-	missing := conv.GetMissingFields()
+// resolvePendingInfo 处理用户对待确认值的答复，返回已确认的信息描述以及消息是否已被处理。
+// 支持：肯定答复确认全部（同一个值对应多个字段的除外）、否定答复丢弃全部、简短答复中指明字段（如「眼镜的」）。
+func (m *Manager) resolvePendingInfo(conv *models.Conversation, text string) ([]string, bool) {
+	groups := pendingGroups(conv)
+	var confirmed []string
+	confirm := func(key string) {
+		pv := conv.PendingInfo[key]
+		if conv.ConfirmPendingInfo(key) {
+			field, _ := models.FindField(key)
+			confirmed = append(confirmed, fmt.Sprintf("%s: %s", field.Name, pv.Value))
+			log.Printf("[Manager] Confirmed pending %s = %q", key, pv.Value)
+		}
+	}
 
-	// 第一次对话（没有提取到任何信息），发送欢迎消息
-	if len(newInfoParts) == 0 && len(conv.Messages) <= 2 {
-		sb.WriteString("您好，我是技术支持助手。/ Hi, I'm the tech support assistant.\n\n")
-		sb.WriteString("📋 反馈问题，请提供以下信息 / To report an issue, please provide:\n")
-		for _, name := range missing {
-			sb.WriteString(fmt.Sprintf("  - %s\n", name))
+	switch {
+	case isAffirmative(text):
+		for _, g := range groups {
+			if len(g.fields) == 1 {
+				confirm(g.fields[0].Key)
+			}
+		}
+		return confirmed, true
+	case isNegative(text):
+		for key := range conv.PendingInfo {
+			conv.DiscardPendingInfo(key)
+		}
+		return nil, true
+	}
+
+	// 简短答复中指明字段：确认该字段，丢弃同一个值的其它候选字段
+	if len([]rune(text)) > 15 {
+		return nil, false
+	}
+	for _, g := range groups {
+		field, ok := models.MatchField(text, g.fields)
+		if !ok {
+			continue
+		}
+		confirm(field.Key)
+		for _, other := range g.fields {
+			if other.Key != field.Key {
+				conv.DiscardPendingInfo(other.Key)
+			}
+		}
+	}
+	return confirmed, len(confirmed) > 0
+}
+
+// pendingGroup 是同一个待确认值及其候选字段。
+type pendingGroup struct {
+	value  string
+	fields []models.FieldDef
+}
+
+// pendingGroups 按值对待确认信息分组（按字段配置顺序），同一个值被提取到多个字段时需用户指明。
+func pendingGroups(conv *models.Conversation) []pendingGroup {
+	var groups []pendingGroup
+	index := make(map[string]int)
+	for _, field := range models.AllFields() {
+		pv, ok := conv.PendingInfo[field.Key]
+		if !ok {
+			continue
+		}
+		if i, ok := index[pv.Value]; ok {
+			groups[i].fields = append(groups[i].fields, field)
+			continue
+		}
+		index[pv.Value] = len(groups)
+		groups = append(groups, pendingGroup{value: pv.Value, fields: []models.FieldDef{field}})
+	}
+	return groups
+}
+
+// pendingQuestions 构建待确认值的提问。
+func pendingQuestions(conv *models.Conversation) []string {
+	var questions []string
+	for _, g := range pendingGroups(conv) {
+		if len(g.fields) == 1 {
+			questions = append(questions, fmt.Sprintf("「%s」是 %s 吗？/ Is this your %s?", g.value, g.fields[0].Name, g.fields[0].Name))
+			continue
 		}
-		// 展示可选字段提示
-		for _, field := range models.OptionalFields {
-			sb.WriteString(fmt.Sprintf("  - %s（可选 / optional）\n", field.Name))
+		names := make([]string, 0, len(g.fields))
+		for _, f := range g.fields {
+			names = append(names, f.Name)
 		}
-		sb.WriteString("\n💡 反馈建议，请直接发送 / To submit a suggestion, send:\n")
-		sb.WriteString("  反馈：您的内容 / feedback: your content\n")
-		sb.WriteString("  建议：您的内容 / suggestion: your content\n")
-		sb.WriteString("\n您可以一次性告诉我，也可以分多次发送。\nYou can provide all info at once or send it in multiple messages.\n")
-		sb.WriteString("如有日志文件，可直接发送附件。\nIf you have log files, feel free to send them as attachments.")
-		return sb.String()
+		questions = append(questions, fmt.Sprintf("「%s」是 %s？请回复对应名称 / Which one is it?", g.value, strings.Join(names, " 还是 / or ")))
+	}
+	return questions
+}
+
+// isAffirmative 判断是否为肯定答复。
+func isAffirmative(text string) bool {
+	return matchReply(text, []string{"是", "是的", "对", "对的", "没错", "确认", "正确", "嗯", "好", "yes", "y", "yep", "yeah", "correct", "right", "confirm", "ok"})
+}
+
+// isNegative 判断是否为否定答复。
+func isNegative(text string) bool {
+	return matchReply(text, []string{"不是", "否", "不对", "错了", "不", "no", "n", "nope", "wrong", "incorrect"})
+}
+
+// matchReply 判断去掉标点后的答复是否与候选词之一完全相同（大小写不敏感）。
+func matchReply(text string, words []string) bool {
+	cleaned := strings.ToLower(strings.TrimFunc(strings.TrimSpace(text), func(r rune) bool {
+		return strings.ContainsRune(".,!?。，！？~ ", r)
+	}))
+	for _, w := range words {
+		if cleaned == w {
+			return true
+		}
+	}
+	return false
+}
+
+// messageTime 返回会话最后一条用户消息的发送时间（用户时区），作为解析相对时间的参照。
+func (m *Manager) messageTime(conv *models.Conversation) time.Time {
+	for i := len(conv.Messages) - 1; i >= 0; i-- {
+		if msg := conv.Messages[i]; msg.Role == "user" && msg.Timestamp > 0 {
+			return time.Unix(msg.Timestamp, 0).In(m.location)
+		}
+	}
+	return time.Now().In(m.location)
+}
+
+// buildSmartResponse 根据合并结果和缺失信息构建回复。
+func (m *Manager) buildSmartResponse(outcome mergeOutcome, conv *models.Conversation) string {
+	var sb strings.Builder
+	missing := conv.GetMissingFields()
+	newInfoParts, rejected := outcome.newParts, outcome.rejected
+
+	// 第一次对话（没有提取到任何信息），发送欢迎消息（补充信息会话除外）
+	if conv.Supplement == nil && len(newInfoParts) == 0 && len(rejected) == 0 && len(outcome.clarifications) == 0 && len(conv.PendingInfo) == 0 && len(conv.Messages) <= 2 {
+		return WelcomeMessage()
 	}
 
 	// 有新收集的信息
@@ -265,9 +471,36 @@ func (m *Manager) buildSmartResponse(newInfoParts []string, conv *models.Convers
 		sb.WriteString("\n")
 	}
 
+	// 有未通过校验的信息，请用户重新提供
+	if len(rejected) > 0 {
+		sb.WriteString("以下信息无法识别，请重新提供 / Please re-enter the following:\n")
+		for _, part := range rejected {
+			sb.WriteString(fmt.Sprintf("  ⚠️ %s\n", part))
+		}
+		sb.WriteString("\n")
+	}
+
+	// 有待确认的信息
+	if questions := pendingQuestions(conv); len(questions) > 0 {
+		sb.WriteString("请确认 / Please confirm:\n")
+		for _, q := range questions {
+			sb.WriteString(fmt.Sprintf("  🤔 %s\n", q))
+		}
+		sb.WriteString("回复「是」确认、「不是」忽略，或直接发送正确信息。/ Reply \"yes\" to confirm, \"no\" to discard, or send the correct info.\n\n")
+	}
+
+	// 有需要补充说明的信息
+	if len(outcome.clarifications) > 0 {
+		sb.WriteString("请补充说明 / Please clarify:\n")
+		for _, q := range outcome.clarifications {
+			sb.WriteString(fmt.Sprintf("  ❓ %s\n", q))
+		}
+		sb.WriteString("\n")
+	}
+
 	// 还有缺失信息
 	if len(missing) > 0 {
-		if len(newInfoParts) == 0 {
+		if len(newInfoParts) == 0 && len(rejected) == 0 && len(outcome.clarifications) == 0 && len(conv.PendingInfo) == 0 {
 			// 用户发了消息但没有提取到新信息
 			sb.WriteString("请继续提供以下信息 / Please provide the following info:\n")
 		} else {
@@ -282,25 +515,47 @@ func (m *Manager) buildSmartResponse(newInfoParts []string, conv *models.Convers
 	return sb.String()
 }
 
-// buildEscalateResponse 构建自动转人工的响应。
-func (m *Manager) buildEscalateResponse(ctx context.Context, conv *models.Conversation) (string, error) {
+// WelcomeMessage 根据字段配置构建欢迎消息（列出必填字段和可选字段）。
+func WelcomeMessage() string {
 	var sb strings.Builder
-	if conv.Mode == models.ModeSuggestion {
-		sb.WriteString("已收到您的反馈/建议，正在提交...\nYour feedback has been received, submitting...\n\n")
-	} else {
-		sb.WriteString("信息收集完毕！/ All info collected!\n\n")
+	sb.WriteString("您好，我是技术支持助手。/ Hi, I'm the tech support assistant.\n\n")
+	sb.WriteString("📋 反馈问题，请提供以下信息 / To report an issue, please provide:\n")
+	for _, field := range models.RequiredFields {
+		if field.IsConditional() {
+			sb.WriteString(fmt.Sprintf("  - %s（如适用 / if applicable）\n", field.Name))
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("  - %s\n", field.Name))
+	}
+	for _, field := range models.OptionalFields {
+		sb.WriteString(fmt.Sprintf("  - %s（可选 / optional）\n", field.Name))
 	}
-	sb.WriteString(conv.GetUserSummary())
-	sb.WriteString("\n正在为您提交到技术支持团队... / Submitting to the support team...")
+	sb.WriteString("\n💡 反馈建议，请直接发送 / To submit a suggestion, send:\n")
+	sb.WriteString("  反馈：您的内容 / feedback: your content\n")
+	sb.WriteString("  建议：您的内容 / suggestion: your content\n")
+	sb.WriteString("\n您可以一次性告诉我，也可以分多次发送。\nYou can provide all info at once or send it in multiple messages.\n")
+	sb.WriteString("如有日志文件，可直接发送附件。\nIf you have log files, feel free to send them as attachments.")
+	return sb.String()
+}
 
-	userMsg := sb.String()
-	conv.AddMessage("assistant", userMsg)
+// buildConfirmResponse 构建信息收集完毕、等待用户确认提交的响应（ConfirmPrefix + 草稿摘要）。
+// 补充信息会话无需确认，返回 SupplementPrefix + 补充内容摘要。
+func (m *Manager) buildConfirmResponse(ctx context.Context, conv *models.Conversation) (string, error) {
+	if conv.Supplement != nil {
+		if err := m.store.SaveConversation(ctx, conv); err != nil {
+			return "", fmt.Errorf("failed to save conversation: %w", err)
+		}
+		return SupplementPrefix + conv.GetSupplementSummary(), nil
+	}
+
+	summary := conv.GetUserSummary()
+	conv.AddMessage("assistant", "信息收集完毕，请确认提交 / All info collected, please confirm.\n\n"+summary)
 
 	if err := m.store.SaveConversation(ctx, conv); err != nil {
 		return "", fmt.Errorf("failed to save conversation: %w", err)
 	}
 
-	return EscalatePrefix + userMsg, nil
+	return ConfirmPrefix + summary, nil
 }
 
 // GetConversation 根据 chat ID 获取会话。
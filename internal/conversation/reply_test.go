@@ -0,0 +1,138 @@
+package conversation
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+func TestMatchReply(t *testing.T) {
+	tests := []struct {
+		text        string
+		affirmative bool
+		negative    bool
+	}{
+		{text: "是", affirmative: true},
+		{text: "是的！", affirmative: true},
+		{text: " Yes. ", affirmative: true},
+		{text: "OK~", affirmative: true},
+		{text: "对，", affirmative: true},
+		{text: "不是", negative: true},
+		{text: "No!", negative: true},
+		{text: "nope", negative: true},
+		{text: "不对。", negative: true},
+		{text: "是眼镜的"},
+		{text: "yes but the ring"},
+		{text: "not sure"},
+		{text: "不知道"},
+		{text: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			if got := isAffirmative(tt.text); got != tt.affirmative {
+				t.Errorf("isAffirmative(%q) = %v, want %v", tt.text, got, tt.affirmative)
+			}
+			if got := isNegative(tt.text); got != tt.negative {
+				t.Errorf("isNegative(%q) = %v, want %v", tt.text, got, tt.negative)
+			}
+		})
+	}
+}
+
+func TestResolvePendingInfo(t *testing.T) {
+	models.SetFieldSchema([]models.FieldDef{
+		{Key: "issue", Name: "问题描述 / Issue Description", ShortName: "问题描述"},
+		{Key: "glasses_sn", Name: "眼镜 SN / Glasses SN", ShortName: "眼镜SN", Aliases: []string{"眼镜", "glasses"}},
+		{Key: "ring_sn", Name: "戒指 SN / Ring SN", ShortName: "戒指SN", Aliases: []string{"戒指", "ring"}},
+		{Key: "app_version", Name: "App 版本 / App Version", ShortName: "App版本"},
+	})
+	defer models.SetFieldSchema(nil)
+
+	pending := func() *models.Conversation {
+		return &models.Conversation{
+			CollectedInfo: map[string]string{"issue": "蓝牙断开"},
+			PendingInfo: map[string]models.PendingValue{
+				"glasses_sn":  {Value: "AB12CD34", Confidence: 0.5},
+				"ring_sn":     {Value: "AB12CD34", Confidence: 0.5},
+				"app_version": {Value: "2.1", Normalized: "2.1.0", Confidence: 0.6},
+			},
+		}
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		handled       bool
+		wantCollected []string
+		wantPending   []string
+	}{
+		{
+			name:          "affirmative confirms single-field values only",
+			text:          "是的",
+			handled:       true,
+			wantCollected: []string{"app_version", "issue"},
+			wantPending:   []string{"glasses_sn", "ring_sn"},
+		},
+		{
+			name:          "negative discards all",
+			text:          "no",
+			handled:       true,
+			wantCollected: []string{"issue"},
+		},
+		{
+			name:          "short reply naming a field picks it",
+			text:          "眼镜的",
+			handled:       true,
+			wantCollected: []string{"glasses_sn", "issue"},
+			wantPending:   []string{"app_version"},
+		},
+		{
+			name:          "unrelated reply is not handled",
+			text:          "还有一个问题",
+			wantCollected: []string{"issue"},
+			wantPending:   []string{"app_version", "glasses_sn", "ring_sn"},
+		},
+		{
+			name:          "long message is not treated as a reply",
+			text:          "眼镜今天又断开了，而且戒指也连不上，手机重启也没用",
+			wantCollected: []string{"issue"},
+			wantPending:   []string{"app_version", "glasses_sn", "ring_sn"},
+		},
+	}
+
+	m := &Manager{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conv := pending()
+			_, handled := m.resolvePendingInfo(conv, tt.text)
+			if handled != tt.handled {
+				t.Errorf("resolvePendingInfo(%q) handled = %v, want %v", tt.text, handled, tt.handled)
+			}
+			if got := sortedKeys(conv.CollectedInfo); !reflect.DeepEqual(got, tt.wantCollected) {
+				t.Errorf("collected = %v, want %v", got, tt.wantCollected)
+			}
+			if got := sortedKeys(conv.PendingInfo); !reflect.DeepEqual(got, tt.wantPending) {
+				t.Errorf("pending = %v, want %v", got, tt.wantPending)
+			}
+		})
+	}
+
+	conv := pending()
+	m.resolvePendingInfo(conv, "yes")
+	if got := conv.NormalizedInfo["app_version"]; got != "2.1.0" {
+		t.Errorf("confirmed app_version normalized = %q, want %q", got, "2.1.0")
+	}
+}
+
+// sortedKeys 返回 map 的 key（排序后，空 map 返回 nil）。
+func sortedKeys[V any](m map[string]V) []string {
+	var keys []string
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,17 @@
+// Package conversation 提供草稿过期（超时提醒 / 过期处理）的会话调度。
+package conversation
+
+import (
+	"context"
+	"time"
+)
+
+// ClaimDueSessionReminders 返回已到过期提醒时间的会话 chatID（每个会话只会被认领一次）。
+func (m *Manager) ClaimDueSessionReminders(ctx context.Context, now time.Time) ([]string, error) {
+	return m.store.ClaimDueSessionReminders(ctx, now)
+}
+
+// ClaimDueSessionExpiries 返回已到过期时间的会话 chatID（每个会话只会被认领一次）。
+func (m *Manager) ClaimDueSessionExpiries(ctx context.Context, now time.Time) ([]string, error) {
+	return m.store.ClaimDueSessionExpiries(ctx, now)
+}
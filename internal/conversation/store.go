@@ -16,12 +16,26 @@ const (
 	ConversationKeyPrefix = "feishu:conv:"
 	// ProcessedMessagesKeyPrefix 是 Redis 中已处理消息ID键的前缀。
 	ProcessedMessagesKeyPrefix = "feishu:processed:"
+	// ArchiveKeyPrefix 是 Redis 中已归档草稿（被清除或过期的最近一份草稿）键的前缀。
+	ArchiveKeyPrefix = "feishu:archive:"
+	// ConfirmDeadlinesKey 是 Redis 中待自动提交会话的有序集合（score 为自动提交时间戳）。
+	ConfirmDeadlinesKey = "feishu:confirm:deadlines"
+	// SessionRemindersKey 是 Redis 中待提醒会话的有序集合（score 为提醒时间戳）。
+	SessionRemindersKey = "feishu:session:reminders"
+	// SessionExpiriesKey 是 Redis 中待过期会话的有序集合（score 为过期时间戳）。
+	SessionExpiriesKey = "feishu:session:expiries"
 )
 
+// DefaultArchiveRetention 是未配置时已归档草稿的保留时间。
+const DefaultArchiveRetention = 7 * 24 * time.Hour
+
 // Store 使用 Redis 处理会话持久化。
 type Store struct {
-	client     *redis.Client
-	expiration time.Duration
+	client           *redis.Client
+	expiration       time.Duration
+	archiveRetention time.Duration // 已归档草稿的保留时间
+	sessionTimeout   time.Duration // 草稿无活动多久后过期（过期时归档），0 表示不跟踪
+	remindBefore     time.Duration // 过期前多久提醒，0 表示不提醒
 }
 
 // NewStore 创建新的 Redis 支持的会话存储。
@@ -45,8 +59,9 @@ func NewStore(addr, password string, db int, expiration int) (*Store, error) {
 	}
 
 	return &Store{
-		client:     rdb,
-		expiration: time.Duration(expiration) * time.Second,
+		client:           rdb,
+		expiration:       time.Duration(expiration) * time.Second,
+		archiveRetention: DefaultArchiveRetention,
 	}, nil
 }
 
@@ -63,6 +78,76 @@ func (s *Store) SaveConversation(ctx context.Context, conv *models.Conversation)
 		return fmt.Errorf("failed to save conversation: %w", err)
 	}
 
+	return s.scheduleSession(ctx, conv)
+}
+
+// SetArchiveRetention 设置已归档草稿的保留时间（不大于 0 时使用默认值）。
+func (s *Store) SetArchiveRetention(retention time.Duration) {
+	if retention <= 0 {
+		retention = DefaultArchiveRetention
+	}
+	s.archiveRetention = retention
+}
+
+// ArchiveConversation 将会话保存为该聊天最近一份归档草稿（覆盖之前的归档）。
+func (s *Store) ArchiveConversation(ctx context.Context, conv *models.Conversation) error {
+	data, err := json.Marshal(conv)
+	if err != nil {
+		return fmt.Errorf("failed to marshal conversation: %w", err)
+	}
+
+	if err := s.client.Set(ctx, s.archiveKey(conv.ChatID), data, s.archiveRetention).Err(); err != nil {
+		return fmt.Errorf("failed to archive conversation: %w", err)
+	}
+	return nil
+}
+
+// GetArchivedConversation 获取归档草稿，不存在时返回 nil。
+func (s *Store) GetArchivedConversation(ctx context.Context, chatID string) (*models.Conversation, error) {
+	data, err := s.client.Get(ctx, s.archiveKey(chatID)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get archived conversation: %w", err)
+	}
+
+	var conv models.Conversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal archived conversation: %w", err)
+	}
+	return &conv, nil
+}
+
+// DeleteArchivedConversation 删除归档草稿。
+func (s *Store) DeleteArchivedConversation(ctx context.Context, chatID string) error {
+	if err := s.client.Del(ctx, s.archiveKey(chatID)).Err(); err != nil {
+		return fmt.Errorf("failed to delete archived conversation: %w", err)
+	}
+	return nil
+}
+
+// SetSessionTimeout 启用草稿过期跟踪：每次保存会话时按 UpdatedAt 重新安排提醒和过期时间。
+func (s *Store) SetSessionTimeout(timeout, remindBefore time.Duration) {
+	s.sessionTimeout = timeout
+	s.remindBefore = remindBefore
+}
+
+// scheduleSession 按会话最后活动时间安排提醒和过期。
+func (s *Store) scheduleSession(ctx context.Context, conv *models.Conversation) error {
+	if s.sessionTimeout <= 0 {
+		return nil
+	}
+	expireAt := conv.UpdatedAt.Add(s.sessionTimeout)
+	pipe := s.client.TxPipeline()
+	pipe.ZAdd(ctx, SessionExpiriesKey, redis.Z{Score: float64(expireAt.Unix()), Member: conv.ChatID})
+	if s.remindBefore > 0 {
+		remindAt := expireAt.Add(-s.remindBefore)
+		pipe.ZAdd(ctx, SessionRemindersKey, redis.Z{Score: float64(remindAt.Unix()), Member: conv.ChatID})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to schedule session expiry: %w", err)
+	}
 	return nil
 }
 
@@ -118,7 +203,7 @@ func (s *Store) GetOrCreateConversation(ctx context.Context, chatID, senderID, s
 	return conv, nil
 }
 
-// ClearConversation 从 Redis 中删除会话。
+// ClearConversation 从 Redis 中删除会话（同时取消待自动提交）。
 func (s *Store) ClearConversation(ctx context.Context, chatID string) error {
 	key := s.conversationKey(chatID)
 
@@ -126,9 +211,75 @@ func (s *Store) ClearConversation(ctx context.Context, chatID string) error {
 		return fmt.Errorf("failed to clear conversation: %w", err)
 	}
 
+	pipe := s.client.TxPipeline()
+	pipe.ZRem(ctx, ConfirmDeadlinesKey, chatID)
+	pipe.ZRem(ctx, SessionRemindersKey, chatID)
+	pipe.ZRem(ctx, SessionExpiriesKey, chatID)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to cancel scheduled jobs: %w", err)
+	}
+	return nil
+}
+
+// ScheduleConfirmDeadline 记录会话的自动提交时间（重复调用会覆盖旧时间）。
+func (s *Store) ScheduleConfirmDeadline(ctx context.Context, chatID string, deadline time.Time) error {
+	err := s.client.ZAdd(ctx, ConfirmDeadlinesKey, redis.Z{
+		Score:  float64(deadline.Unix()),
+		Member: chatID,
+	}).Err()
+	if err != nil {
+		return fmt.Errorf("failed to schedule confirm deadline: %w", err)
+	}
+	return nil
+}
+
+// CancelConfirmDeadline 取消会话的自动提交。
+func (s *Store) CancelConfirmDeadline(ctx context.Context, chatID string) error {
+	if err := s.client.ZRem(ctx, ConfirmDeadlinesKey, chatID).Err(); err != nil {
+		return fmt.Errorf("failed to cancel confirm deadline: %w", err)
+	}
 	return nil
 }
 
+// ClaimDueConfirmDeadlines 取出已到自动提交时间的会话。
+func (s *Store) ClaimDueConfirmDeadlines(ctx context.Context, now time.Time) ([]string, error) {
+	return s.claimDue(ctx, ConfirmDeadlinesKey, now)
+}
+
+// ClaimDueSessionReminders 取出已到提醒时间的会话。
+func (s *Store) ClaimDueSessionReminders(ctx context.Context, now time.Time) ([]string, error) {
+	return s.claimDue(ctx, SessionRemindersKey, now)
+}
+
+// ClaimDueSessionExpiries 取出已到过期时间的会话。
+func (s *Store) ClaimDueSessionExpiries(ctx context.Context, now time.Time) ([]string, error) {
+	return s.claimDue(ctx, SessionExpiriesKey, now)
+}
+
+// claimDue 从有序集合中取出 score 不晚于 now 的成员。
+// 每个成员通过 ZREM 原子认领，多实例部署时只有一个实例会处理。
+func (s *Store) claimDue(ctx context.Context, key string, now time.Time) ([]string, error) {
+	members, err := s.client.ZRangeByScore(ctx, key, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", now.Unix()),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", key, err)
+	}
+
+	var claimed []string
+	for _, member := range members {
+		n, err := s.client.ZRem(ctx, key, member).Result()
+		if err != nil {
+			return claimed, fmt.Errorf("failed to claim %s: %w", key, err)
+		}
+		if n == 1 {
+			claimed = append(claimed, member)
+		}
+	}
+	return claimed, nil
+}
+
 // TryMarkMessageProcessed 原子性地检查并标记消息为已处理。
 // 使用 SETNX（SetNX）实现原子操作，避免竞态条件。
 // 返回 true 表示消息是新的（首次标记成功），false 表示消息已处理过。
@@ -152,6 +303,11 @@ func (s *Store) conversationKey(chatID string) string {
 	return ConversationKeyPrefix + chatID
 }
 
+// archiveKey 返回归档草稿的 Redis 键。
+func (s *Store) archiveKey(chatID string) string {
+	return ArchiveKeyPrefix + chatID
+}
+
 // Client 返回底层的 Redis 客户端。
 func (s *Store) Client() *redis.Client {
 	return s.client
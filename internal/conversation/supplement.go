@@ -0,0 +1,64 @@
+// Package conversation 提供技术支持发起的补充信息会话。
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// ErrDraftInProgress 表示用户正在填写新的草稿，不能开始补充信息会话。
+var ErrDraftInProgress = errors.New("a draft is in progress")
+
+// StartSupplement 为工单开始补充信息会话：会话预填工单中已有的信息（请求补充的字段除外），
+// 之后用户的消息按正常提取 / 校验流程合并，请求的字段和附件收齐后返回 SupplementPrefix。
+// 用户正在填写其他草稿时返回 ErrDraftInProgress。
+func (m *Manager) StartSupplement(ctx context.Context, t *models.Ticket, req models.SupplementRequest) (*models.Conversation, error) {
+	current, err := m.store.GetConversation(ctx, t.ChatID)
+	if err != nil {
+		return nil, err
+	}
+	if current != nil && current.HasDraft() && (current.Supplement == nil || current.Supplement.TicketID != t.ID) {
+		return current, ErrDraftInProgress
+	}
+
+	requested := make(map[string]bool, len(req.Fields))
+	for _, key := range req.Fields {
+		requested[key] = true
+	}
+	collected := make(map[string]string)
+	normalized := make(map[string]string)
+	for key, val := range t.CollectedInfo {
+		if !requested[key] {
+			collected[key] = val
+		}
+	}
+	for key, val := range t.NormalizedInfo {
+		if !requested[key] {
+			normalized[key] = val
+		}
+	}
+
+	now := time.Now()
+	conv := &models.Conversation{
+		ChatID:         t.ChatID,
+		SenderID:       t.UserID,
+		SenderName:     t.UserName,
+		Messages:       []models.Message{},
+		Mode:           models.ModeIssue,
+		CollectedInfo:  collected,
+		NormalizedInfo: normalized,
+		Supplement:     &req,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+	if err := m.store.SaveConversation(ctx, conv); err != nil {
+		return nil, fmt.Errorf("failed to save conversation: %w", err)
+	}
+	log.Printf("[Manager] Supplement for ticket %s started in chat %s: fields=%v, files=%v", t.ID, t.ChatID, req.Fields, req.Files)
+	return conv, nil
+}
@@ -0,0 +1,428 @@
+// Package conversation 提供发生时间的自然语言解析。
+package conversation
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrAmbiguousTime 表示时间描述不够具体，无法解析为绝对时间。
+var ErrAmbiguousTime = errors.New("ambiguous time")
+
+// dayPeriod 表示一天中的时段。
+type dayPeriod int
+
+const (
+	periodNone      dayPeriod = iota
+	periodDawn                // 凌晨
+	periodMorning             // 早上 / 上午
+	periodNoon                // 中午
+	periodAfternoon           // 下午
+	periodEvening             // 傍晚 / 晚上
+)
+
+// periodDefaultHour 是只给出时段、未给出具体钟点时采用的代表时刻。
+var periodDefaultHour = map[dayPeriod]int{
+	periodDawn:      3,
+	periodMorning:   9,
+	periodNoon:      12,
+	periodAfternoon: 15,
+	periodEvening:   20,
+}
+
+var (
+	reFullDate    = regexp.MustCompile(`(\d{4})\s*[-/.年]\s*(\d{1,2})\s*[-/.月]\s*(\d{1,2})\s*[日号]?`)
+	reMonthDay    = regexp.MustCompile(`(\d{1,2})\s*月\s*(\d{1,2})\s*[日号]`)
+	reEnMonthDay  = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|oct|nov|dec)[a-z]*\.?\s+(\d{1,2})(?:st|nd|rd|th)?\b`)
+	reAgoZh       = regexp.MustCompile(`([0-9]+(?:\.[0-9]+)?|[一二两三四五六七八九十]+)?\s*(?:个)?\s*(半)?\s*(?:个)?\s*(分钟|小时|钟头|天)(?:之)?前`)
+	reAgoEn       = regexp.MustCompile(`(?i)\b(\d+(?:\.\d+)?|an?|half an?)\s*(minutes?|mins?|hours?|hrs?|days?)\s+ago\b`)
+	reZhWeekday   = regexp.MustCompile(`(上上|上|这|本)?\s*(?:周|星期|礼拜)([一二三四五六日天])`)
+	reEnWeekday   = regexp.MustCompile(`(?i)\b(last\s+|this\s+)?(monday|tuesday|wednesday|thursday|friday|saturday|sunday)\b`)
+	reClock       = regexp.MustCompile(`(\d{1,2})\s*[:：]\s*(\d{2})(?:\s*(am|pm|a\.m\.|p\.m\.))?`)
+	reEnHour      = regexp.MustCompile(`(?i)\b(\d{1,2})\s*(am|pm|a\.m\.|p\.m\.)`)
+	reZhHour      = regexp.MustCompile(`([0-9]{1,2}|[零一二两三四五六七八九十]+)\s*[点點时](半|一刻|三刻|([0-9]{1,2}|[零一二三四五六七八九十]+)\s*分?)?`)
+	enMonthNumber = map[string]time.Month{
+		"jan": time.January, "feb": time.February, "mar": time.March, "apr": time.April,
+		"may": time.May, "jun": time.June, "jul": time.July, "aug": time.August,
+		"sep": time.September, "oct": time.October, "nov": time.November, "dec": time.December,
+	}
+	zhWeekday = map[string]time.Weekday{
+		"一": time.Monday, "二": time.Tuesday, "三": time.Wednesday, "四": time.Thursday,
+		"五": time.Friday, "六": time.Saturday, "日": time.Sunday, "天": time.Sunday,
+	}
+	enWeekday = map[string]time.Weekday{
+		"monday": time.Monday, "tuesday": time.Tuesday, "wednesday": time.Wednesday, "thursday": time.Thursday,
+		"friday": time.Friday, "saturday": time.Saturday, "sunday": time.Sunday,
+	}
+)
+
+// ParseOccurTime 将用户描述的发生时间（如 "今天下午3点"、"yesterday evening"）解析为绝对时间。
+// ref 为描述所在消息的发送时间，需已转换到用户时区；解析结果与 ref 处于同一时区。
+// 描述缺少钟点（如只有 "昨天"）、钟点无法区分上下午或无法识别时返回 ErrAmbiguousTime。
+func ParseOccurTime(text string, ref time.Time) (time.Time, error) {
+	lower := strings.ToLower(strings.TrimSpace(text))
+	if lower == "" {
+		return time.Time{}, ErrAmbiguousTime
+	}
+
+	// 1. 相对当前的时间："刚才"、"半小时前"、"2 hours ago"
+	if containsAny(lower, "刚才", "刚刚", "just now", "right now", "现在") {
+		return ref.Truncate(time.Minute), nil
+	}
+	if d, unit, ok := parseAgo(lower); ok {
+		t := ref.Add(-d)
+		if unit != "day" {
+			return t.Truncate(time.Minute), nil
+		}
+		// "3天前" 只能确定日期，继续解析钟点
+		return resolveOnDate(lower, ref, t.Year(), t.Month(), t.Day())
+	}
+
+	// 2. 日期：绝对日期或相对日期
+	year, month, day, hasDate := parseDate(lower, ref)
+	if !hasDate {
+		// "上周"、"last week" 没有说明星期几，无法确定日期
+		if containsAny(lower, "上周", "上星期", "上礼拜", "last week") {
+			return time.Time{}, ErrAmbiguousTime
+		}
+		year, month, day = ref.Date()
+	}
+	t, err := resolveOnDate(lower, ref, year, month, day)
+	if err != nil {
+		return time.Time{}, err
+	}
+	// 没有给出日期且解析结果晚于消息时间，按前一天处理（如上午发消息说"晚上8点"）
+	if !hasDate && t.After(ref) {
+		t = t.AddDate(0, 0, -1)
+	}
+	if t.After(ref.Add(time.Hour)) {
+		return time.Time{}, ErrAmbiguousTime
+	}
+	return t, nil
+}
+
+// resolveOnDate 在给定日期上解析钟点和时段。
+func resolveOnDate(lower string, ref time.Time, year int, month time.Month, day int) (time.Time, error) {
+	period := parsePeriod(lower)
+	hour, minute, explicitPeriod, hasClock := parseClock(lower)
+
+	switch {
+	case hasClock:
+		switch {
+		case explicitPeriod == period24h && period != periodNone && hour <= 12:
+			// "晚上 8:30"：HH:MM 与时段同时出现时按时段换算
+		case explicitPeriod != periodNone:
+			period = explicitPeriod
+		}
+		h, ok := applyPeriod(hour, period)
+		if !ok {
+			return time.Time{}, ErrAmbiguousTime
+		}
+		hour = h
+	case period != periodNone:
+		hour, minute = periodDefaultHour[period], 0
+	default:
+		return time.Time{}, ErrAmbiguousTime // 只有日期，没有钟点
+	}
+
+	if hour > 24 || minute > 59 {
+		return time.Time{}, ErrAmbiguousTime
+	}
+	// hour 为 24 时 time.Date 会进位到次日 0 点
+	return time.Date(year, month, day, hour, minute, 0, 0, ref.Location()), nil
+}
+
+// parseAgo 解析 "N分钟前 / N hours ago" 形式，返回时长和单位。
+func parseAgo(lower string) (time.Duration, string, bool) {
+	var amount float64
+	var unit string
+	if m := reAgoZh.FindStringSubmatch(lower); m != nil && (m[1] != "" || m[2] != "") {
+		// "一个半小时前"：整数部分和 "半" 分开匹配
+		if m[1] != "" {
+			n, ok := parseChineseNumber(m[1])
+			if !ok {
+				return 0, "", false
+			}
+			amount = n
+		}
+		if m[2] != "" {
+			amount += 0.5
+		}
+		switch m[3] {
+		case "分钟":
+			unit = "minute"
+		case "小时", "钟头":
+			unit = "hour"
+		default:
+			unit = "day"
+		}
+	} else if m := reAgoEn.FindStringSubmatch(lower); m != nil {
+		switch {
+		case strings.HasPrefix(m[1], "half"):
+			amount = 0.5
+		case m[1] == "a" || m[1] == "an":
+			amount = 1
+		default:
+			amount, _ = strconv.ParseFloat(m[1], 64)
+		}
+		switch {
+		case strings.HasPrefix(m[2], "min"):
+			unit = "minute"
+		case strings.HasPrefix(m[2], "h"):
+			unit = "hour"
+		default:
+			unit = "day"
+		}
+	} else {
+		return 0, "", false
+	}
+
+	switch unit {
+	case "minute":
+		return time.Duration(amount * float64(time.Minute)), unit, true
+	case "hour":
+		return time.Duration(amount * float64(time.Hour)), unit, true
+	default:
+		if amount != float64(int(amount)) {
+			return 0, "", false // "一天半前" 无法确定日期
+		}
+		return time.Duration(amount) * 24 * time.Hour, unit, true
+	}
+}
+
+// parseDate 解析绝对日期（2026-02-09、2月9日、Feb 9）或相对日期（今天、昨天、前天）。
+func parseDate(lower string, ref time.Time) (int, time.Month, int, bool) {
+	if m := reFullDate.FindStringSubmatch(lower); m != nil {
+		y, _ := strconv.Atoi(m[1])
+		mo, _ := strconv.Atoi(m[2])
+		d, _ := strconv.Atoi(m[3])
+		return y, time.Month(mo), d, true
+	}
+	if m := reMonthDay.FindStringSubmatch(lower); m != nil {
+		mo, _ := strconv.Atoi(m[1])
+		d, _ := strconv.Atoi(m[2])
+		return yearBefore(ref, time.Month(mo), d), time.Month(mo), d, true
+	}
+	if m := reEnMonthDay.FindStringSubmatch(lower); m != nil {
+		mo := enMonthNumber[strings.ToLower(m[1])]
+		d, _ := strconv.Atoi(m[2])
+		return yearBefore(ref, mo, d), mo, d, true
+	}
+	if t, ok := parseWeekday(lower, ref); ok {
+		return t.Year(), t.Month(), t.Day(), true
+	}
+
+	offset := 0
+	switch {
+	case containsAny(lower, "大前天"):
+		offset = -3
+	case containsAny(lower, "前天", "day before yesterday"):
+		offset = -2
+	case containsAny(lower, "昨天", "昨晚", "昨日", "yesterday", "last night"):
+		offset = -1
+	case containsAny(lower, "今天", "今早", "今晚", "今日", "today", "this morning", "this afternoon", "this evening", "tonight"):
+		offset = 0
+	default:
+		return 0, 0, 0, false
+	}
+	t := ref.AddDate(0, 0, offset)
+	return t.Year(), t.Month(), t.Day(), true
+}
+
+// parseWeekday 解析星期描述："上周五" 指上一个自然周（周一开始）的周五，"这周五" 指本周的周五，
+// 只说 "周五" / "friday" 时取不晚于今天的最近一个周五，"last friday" 取今天之前最近的一个周五。
+func parseWeekday(lower string, ref time.Time) (time.Time, bool) {
+	today := time.Date(ref.Year(), ref.Month(), ref.Day(), 0, 0, 0, 0, ref.Location())
+	if m := reZhWeekday.FindStringSubmatch(lower); m != nil {
+		wd := zhWeekday[m[2]]
+		if m[1] == "" {
+			return mostRecentWeekday(today, wd, false), true
+		}
+		weeks := 0
+		switch m[1] {
+		case "上":
+			weeks = -1
+		case "上上":
+			weeks = -2
+		}
+		// 本周周一 + 周数偏移 + 星期偏移（周日为一周的第 7 天）
+		monday := today.AddDate(0, 0, -((int(today.Weekday()) + 6) % 7))
+		return monday.AddDate(0, 0, weeks*7+(int(wd)+6)%7), true
+	}
+	if m := reEnWeekday.FindStringSubmatch(lower); m != nil {
+		wd := enWeekday[strings.ToLower(m[2])]
+		return mostRecentWeekday(today, wd, strings.HasPrefix(strings.ToLower(m[1]), "last")), true
+	}
+	return time.Time{}, false
+}
+
+// mostRecentWeekday 返回不晚于 today（strict 为 true 时早于 today）的最近一个星期 wd。
+func mostRecentWeekday(today time.Time, wd time.Weekday, strict bool) time.Time {
+	back := (int(today.Weekday()) - int(wd) + 7) % 7
+	if back == 0 && strict {
+		back = 7
+	}
+	return today.AddDate(0, 0, -back)
+}
+
+// yearBefore 返回不晚于 ref 的 month/day 所在年份（未写年份的日期默认指过去）。
+func yearBefore(ref time.Time, month time.Month, day int) int {
+	year := ref.Year()
+	if time.Date(year, month, day, 0, 0, 0, 0, ref.Location()).After(ref) {
+		year--
+	}
+	return year
+}
+
+// parsePeriod 识别描述中的时段。
+func parsePeriod(lower string) dayPeriod {
+	switch {
+	case containsAny(lower, "凌晨", "半夜", "midnight"):
+		return periodDawn
+	case containsAny(lower, "下午", "afternoon"):
+		return periodAfternoon
+	case containsAny(lower, "晚上", "傍晚", "昨晚", "今晚", "夜里", "evening", "tonight", "last night", "night"):
+		return periodEvening
+	case containsAny(lower, "中午", "noon"):
+		return periodNoon
+	case containsAny(lower, "早上", "上午", "早晨", "今早", "morning"):
+		return periodMorning
+	}
+	return periodNone
+}
+
+// parseClock 解析钟点，返回小时、分钟、钟点自带的时段以及是否找到钟点。
+// HH:MM 无 am/pm 时按 24 小时制处理（返回 period24h）。
+func parseClock(lower string) (int, int, dayPeriod, bool) {
+	if m := reClock.FindStringSubmatch(lower); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		mi, _ := strconv.Atoi(m[2])
+		if m[3] != "" {
+			return h, mi, amPmPeriod(m[3]), true
+		}
+		return h, mi, period24h, true
+	}
+	if m := reEnHour.FindStringSubmatch(lower); m != nil {
+		h, _ := strconv.Atoi(m[1])
+		return h, 0, amPmPeriod(m[2]), true
+	}
+	if m := reZhHour.FindStringSubmatch(lower); m != nil {
+		h, ok := parseChineseNumber(m[1])
+		if !ok {
+			return 0, 0, periodNone, false
+		}
+		minute := 0
+		switch {
+		case m[2] == "半":
+			minute = 30
+		case m[2] == "一刻":
+			minute = 15
+		case m[2] == "三刻":
+			minute = 45
+		case m[3] != "":
+			if n, ok := parseChineseNumber(m[3]); ok {
+				minute = int(n)
+			}
+		}
+		hour := int(h)
+		if hour > 12 || hour == 0 {
+			return hour, minute, period24h, true
+		}
+		return hour, minute, periodNone, true
+	}
+	return 0, 0, periodNone, false
+}
+
+// period24h 标记钟点已是 24 小时制，无需按时段换算。
+const period24h dayPeriod = -1
+
+// amPmPeriod 将 am/pm 转为时段。
+func amPmPeriod(s string) dayPeriod {
+	if strings.HasPrefix(strings.ToLower(s), "p") {
+		return periodAfternoon
+	}
+	return periodMorning
+}
+
+// applyPeriod 按时段将 12 小时制钟点换算为 24 小时制；无法区分上下午时返回 false。
+func applyPeriod(hour int, period dayPeriod) (int, bool) {
+	switch period {
+	case period24h:
+		return hour, true
+	case periodDawn:
+		if hour == 12 {
+			return 0, true
+		}
+		return hour, true
+	case periodMorning:
+		if hour == 12 {
+			return 0, true
+		}
+		return hour, true
+	case periodNoon:
+		if hour >= 1 && hour <= 3 {
+			return hour + 12, true
+		}
+		return hour, true
+	case periodAfternoon:
+		if hour < 12 {
+			return hour + 12, true
+		}
+		return hour, true
+	case periodEvening:
+		if hour == 12 {
+			return 24, true // "晚上12点" 指当天结束时的午夜，即次日 0 点
+		}
+		if hour < 12 {
+			return hour + 12, true
+		}
+		return hour, true
+	default:
+		return 0, false // "3点" 无法区分凌晨还是下午
+	}
+}
+
+// parseChineseNumber 解析阿拉伯数字或简单中文数字（如 "3"、"十一"、"两"、"半"）。
+func parseChineseNumber(s string) (float64, bool) {
+	if n, err := strconv.Atoi(s); err == nil {
+		return float64(n), true
+	}
+	if s == "半" {
+		return 0.5, true
+	}
+	digits := map[rune]int{'零': 0, '一': 1, '二': 2, '两': 2, '三': 3, '四': 4, '五': 5, '六': 6, '七': 7, '八': 8, '九': 9}
+	runes := []rune(s)
+	total, current := 0, 0
+	for _, r := range runes {
+		switch {
+		case r == '十':
+			if current == 0 {
+				current = 1
+			}
+			total += current * 10
+			current = 0
+		case r == '半':
+			return float64(total+current) + 0.5, true
+		default:
+			d, ok := digits[r]
+			if !ok {
+				return 0, false
+			}
+			current = d
+		}
+	}
+	return float64(total + current), true
+}
+
+// containsAny 判断 s 是否包含任一子串。
+func containsAny(s string, subs ...string) bool {
+	for _, sub := range subs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,80 @@
+package conversation
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseOccurTime(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// 2026-10-16 是周五
+	ref := time.Date(2026, 10, 16, 10, 30, 0, 0, loc)
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, loc)
+	}
+
+	tests := []struct {
+		text      string
+		want      time.Time
+		ambiguous bool
+	}{
+		{text: "刚才", want: at(10, 16, 10, 30)},
+		{text: "半小时前", want: at(10, 16, 10, 0)},
+		{text: "一个半小时前", want: at(10, 16, 9, 0)},
+		{text: "两个小时前", want: at(10, 16, 8, 30)},
+		{text: "1.5 hours ago", want: at(10, 16, 9, 0)},
+		{text: "half an hour ago", want: at(10, 16, 10, 0)},
+		{text: "3天前下午2点", want: at(10, 13, 14, 0)},
+
+		{text: "今天上午9点", want: at(10, 16, 9, 0)},
+		{text: "昨天下午3点半", want: at(10, 15, 15, 30)},
+		{text: "yesterday evening", want: at(10, 15, 20, 0)},
+		{text: "昨晚 8:30", want: at(10, 15, 20, 30)},
+		{text: "晚上8点", want: at(10, 15, 20, 0)},
+		{text: "2026-10-14 21:05", want: at(10, 14, 21, 5)},
+		{text: "10月12日早上7点", want: at(10, 12, 7, 0)},
+		{text: "Oct 12 7pm", want: at(10, 12, 19, 0)},
+
+		{text: "上周五下午3点", want: at(10, 9, 15, 0)},
+		{text: "last friday 3pm", want: at(10, 9, 15, 0)},
+		{text: "上周日晚上", want: at(10, 11, 20, 0)},
+		{text: "上上周一上午10点", want: at(9, 28, 10, 0)},
+		{text: "周三下午4点", want: at(10, 14, 16, 0)},
+		{text: "wednesday 4pm", want: at(10, 14, 16, 0)},
+
+		{text: "晚上12点", want: at(10, 16, 0, 0)},
+		{text: "昨天晚上12点", want: at(10, 16, 0, 0)},
+		{text: "凌晨12点半", want: at(10, 16, 0, 30)},
+		{text: "中午12点", want: at(10, 15, 12, 0)},
+
+		{text: "上周", ambiguous: true},
+		{text: "last week", ambiguous: true},
+		{text: "昨天", ambiguous: true},
+		{text: "3点", ambiguous: true},
+		{text: "今晚12点", ambiguous: true},
+		{text: "一天半前", ambiguous: true},
+		{text: "不记得了", ambiguous: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			got, err := ParseOccurTime(tt.text, ref)
+			if tt.ambiguous {
+				if !errors.Is(err, ErrAmbiguousTime) {
+					t.Fatalf("ParseOccurTime(%q) = %v, %v, want ErrAmbiguousTime", tt.text, got, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseOccurTime(%q) unexpected error: %v", tt.text, err)
+			}
+			if !got.Equal(tt.want) {
+				t.Errorf("ParseOccurTime(%q) = %s, want %s", tt.text, got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package conversation 提供字段校验与标准化功能。
+package conversation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// defaultSNPattern 是未配置 pattern 时 SN 字段使用的正则。
+const defaultSNPattern = `^[A-Z0-9-]{6,32}$`
+
+// versionPattern 匹配语义化版本号（至少包含主版本号和次版本号）。
+var versionPattern = regexp.MustCompile(`(?i)v?(\d+)\.(\d+)(?:\.(\d+))?(?:\.(\d+))?`)
+
+// patternCache 缓存已编译的字段正则，避免每条消息重复编译。
+var patternCache sync.Map // map[pattern]*regexp.Regexp
+
+// ValidationError 表示字段值未通过校验。
+type ValidationError struct {
+	Field  models.FieldDef
+	Value  string
+	Reason string // 中英双语原因
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Field.Key, e.Value, e.Reason)
+}
+
+// NormalizeFieldValue 按字段配置校验并标准化值，ref 为值所在消息的发送时间（用户时区，datetime 使用）。
+// 返回标准值；未配置校验规则的字段返回空字符串（只保留原文）。
+// datetime 字段描述不够具体时返回 ErrAmbiguousTime，调用方应保留原文并请用户补充。
+func NormalizeFieldValue(field models.FieldDef, raw string, ref time.Time) (string, error) {
+	raw = strings.TrimSpace(raw)
+	switch field.Validate {
+	case "":
+		return "", nil
+	case models.ValidateVersion:
+		return normalizeVersion(field, raw)
+	case models.ValidateSN:
+		return normalizeSN(field, raw)
+	case models.ValidateEnum:
+		return normalizeEnum(field, raw)
+	case models.ValidateRegex:
+		return normalizeRegex(field, raw)
+	case models.ValidateDatetime:
+		t, err := ParseOccurTime(raw, ref)
+		if err != nil {
+			return "", err
+		}
+		return t.Format(time.RFC3339), nil
+	default:
+		return "", nil
+	}
+}
+
+// normalizeVersion 解析语义化版本号，标准化为 MAJOR.MINOR.PATCH（有第四段时保留）。
+func normalizeVersion(field models.FieldDef, raw string) (string, error) {
+	m := versionPattern.FindStringSubmatch(raw)
+	if m == nil {
+		return "", &ValidationError{Field: field, Value: raw, Reason: "未识别到版本号 / no version number found"}
+	}
+	patch := m[3]
+	if patch == "" {
+		patch = "0"
+	}
+	version := fmt.Sprintf("%s.%s.%s", m[1], m[2], patch)
+	if m[4] != "" {
+		version += "." + m[4]
+	}
+	return version, nil
+}
+
+// normalizeSN 去除空白并转大写后按正则校验序列号。
+func normalizeSN(field models.FieldDef, raw string) (string, error) {
+	sn := models.NormalizeSN(raw)
+	pattern := field.Pattern
+	if pattern == "" {
+		pattern = defaultSNPattern
+	}
+	re, err := compilePattern(pattern)
+	if err != nil {
+		return "", err
+	}
+	if !re.MatchString(sn) {
+		return "", &ValidationError{Field: field, Value: raw, Reason: "SN 格式不正确 / invalid SN format"}
+	}
+	return sn, nil
+}
+
+// normalizeEnum 按同义词将值归一到枚举标准值（最长前缀匹配，支持 "yes, US node" 这类带补充说明的回答）。
+func normalizeEnum(field models.FieldDef, raw string) (string, error) {
+	lower := strings.ToLower(raw)
+	best, bestLen := "", 0
+	for _, opt := range field.Enum {
+		candidates := append([]string{opt.Value}, opt.Synonyms...)
+		for _, syn := range candidates {
+			syn = strings.ToLower(strings.TrimSpace(syn))
+			if syn == "" || len(syn) <= bestLen || !hasWordPrefix(lower, syn) {
+				continue
+			}
+			best, bestLen = opt.Value, len(syn)
+		}
+	}
+	if best == "" {
+		return "", &ValidationError{Field: field, Value: raw, Reason: "无法识别的选项 / unrecognized option"}
+	}
+	return best, nil
+}
+
+// hasWordPrefix 判断 s 是否以 prefix 开头，且 prefix 之后不是紧跟的字母（避免 "no" 匹配 "not sure"）。
+func hasWordPrefix(s, prefix string) bool {
+	if !strings.HasPrefix(s, prefix) {
+		return false
+	}
+	rest := []rune(s[len(prefix):])
+	if len(rest) == 0 {
+		return true
+	}
+	last := []rune(prefix)[len([]rune(prefix))-1]
+	if last > unicode.MaxASCII {
+		// 中文前缀无需单词边界，但单字同义词（如 "有"、"是"）须完整匹配（允许结尾标点），
+		// 避免 "有眼镜没有戒指"、"是不是要戒指" 被识别为 "有" / "是"
+		if len([]rune(prefix)) == 1 {
+			return strings.TrimFunc(string(rest), func(r rune) bool {
+				return unicode.IsPunct(r) || unicode.IsSpace(r)
+			}) == ""
+		}
+		return true
+	}
+	return !unicode.IsLetter(rest[0]) && !unicode.IsDigit(rest[0])
+}
+
+// normalizeRegex 要求值中包含正则匹配，标准化为匹配到的部分。
+func normalizeRegex(field models.FieldDef, raw string) (string, error) {
+	re, err := compilePattern(field.Pattern)
+	if err != nil {
+		return "", err
+	}
+	match := re.FindString(raw)
+	if match == "" {
+		return "", &ValidationError{Field: field, Value: raw, Reason: "格式不正确 / invalid format"}
+	}
+	return match, nil
+}
+
+// compilePattern 编译并缓存正则。
+func compilePattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := patternCache.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid field pattern %q: %w", pattern, err)
+	}
+	patternCache.Store(pattern, re)
+	return re, nil
+}
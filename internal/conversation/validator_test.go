@@ -0,0 +1,92 @@
+package conversation
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+func TestNormalizeFieldValue(t *testing.T) {
+	version := models.FieldDef{Key: "app_version", Validate: models.ValidateVersion}
+	sn := models.FieldDef{Key: "glasses_sn", Validate: models.ValidateSN}
+	customSN := models.FieldDef{Key: "ring_sn", Validate: models.ValidateSN, Pattern: `^R[0-9]{4}$`}
+	enum := models.FieldDef{Key: "reproducible", Validate: models.ValidateEnum, Enum: []models.EnumOption{
+		{Value: "yes", Synonyms: []string{"是", "能", "always"}},
+		{Value: "no", Synonyms: []string{"否", "不能"}},
+		{Value: "sometimes", Synonyms: []string{"偶尔", "有时"}},
+	}}
+	ring := models.FieldDef{Key: "uses_ring", Validate: models.ValidateEnum, Enum: []models.EnumOption{
+		{Value: "yes", Synonyms: []string{"y", "是", "是的", "有", "有戒指", "在用"}},
+		{Value: "no", Synonyms: []string{"n", "否", "不是", "没有", "没有戒指", "只有眼镜"}},
+	}}
+	regex := models.FieldDef{Key: "order", Validate: models.ValidateRegex, Pattern: `[0-9]{8}`}
+	plain := models.FieldDef{Key: "description"}
+
+	tests := []struct {
+		name    string
+		field   models.FieldDef
+		raw     string
+		want    string
+		invalid bool
+	}{
+		{"version with v prefix", version, "v2.0.6", "2.0.6", false},
+		{"version missing patch", version, "App 版本 2.1", "2.1.0", false},
+		{"version with build", version, "1.2.3.4567", "1.2.3.4567", false},
+		{"version not found", version, "最新版", "", true},
+
+		{"sn uppercased and trimmed", sn, " ab12 cd34 ", "AB12CD34", false},
+		{"sn too short", sn, "ab1", "", true},
+		{"sn invalid chars", sn, "AB12#CD34", "", true},
+		{"sn custom pattern", customSN, "r1234", "R1234", false},
+		{"sn custom pattern mismatch", customSN, "X1234", "", true},
+
+		{"enum value", enum, "Yes", "yes", false},
+		{"enum synonym", enum, "偶尔会出现", "sometimes", false},
+		{"enum prefix with note", enum, "yes, US node", "yes", false},
+		{"enum longest synonym wins", enum, "不能复现", "no", false},
+		{"enum no word boundary", enum, "not sure", "", true},
+		{"enum unknown", enum, "maybe", "", true},
+		{"enum single CJK exact", ring, "有", "yes", false},
+		{"enum single CJK with punctuation", ring, "是！", "yes", false},
+		{"enum single CJK not a prefix", ring, "有眼镜没有戒指", "", true},
+		{"enum single CJK question", ring, "是不是要戒指", "", true},
+		{"enum multi CJK prefix", ring, "没有戒指，只有眼镜", "no", false},
+		{"enum CJK longest wins", ring, "有戒指的", "yes", false},
+
+		{"regex match extracted", regex, "订单号 20261016 谢谢", "20261016", false},
+		{"regex no match", regex, "订单号 123", "", true},
+
+		{"no validation keeps raw only", plain, "蓝牙断开", "", false},
+	}
+
+	ref := time.Date(2026, 10, 16, 10, 30, 0, 0, time.UTC)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeFieldValue(tt.field, tt.raw, ref)
+			if tt.invalid {
+				var verr *ValidationError
+				if !errors.As(err, &verr) {
+					t.Fatalf("NormalizeFieldValue(%q) error = %v, want ValidationError", tt.raw, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeFieldValue(%q) unexpected error: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizeFieldValue(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeFieldValueInvalidPattern(t *testing.T) {
+	field := models.FieldDef{Key: "order", Validate: models.ValidateRegex, Pattern: `[`}
+	_, err := NormalizeFieldValue(field, "abc", time.Now())
+	var verr *ValidationError
+	if err == nil || errors.As(err, &verr) {
+		t.Fatalf("NormalizeFieldValue with invalid pattern error = %v, want config error", err)
+	}
+}
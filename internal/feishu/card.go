@@ -0,0 +1,185 @@
+// Package feishu 提供交互式卡片的构建工具。
+package feishu
+
+import (
+	"fmt"
+	"time"
+)
+
+// 卡片按钮回调 value 中的动作标识。
+const (
+	CardActionKey    = "action" // 按钮 value 中表示动作的键
+	CardActionSubmit = "submit" // 确认提交
+	CardActionEdit   = "edit"   // 继续编辑
+	CardActionCancel = "cancel" // 取消并清除草稿
+
+	CardActionFormSubmit = "form_submit" // 提交表单
+	CardActionCSATSubmit = "csat_submit" // 提交满意度评价
+
+	CardTicketKey = "ticket" // 按钮 value 中表示工单号的键
+)
+
+// 卡片标题颜色模板。
+const (
+	CardTemplateBlue  = "blue"
+	CardTemplateGreen = "green"
+	CardTemplateGrey  = "grey"
+)
+
+// BuildConfirmCard 构建提交确认卡片：草稿摘要 + 提交 / 编辑 / 取消按钮。
+// autoSubmit 大于 0 时在卡片底部提示超时自动提交。
+func BuildConfirmCard(summary string, autoSubmit time.Duration) map[string]interface{} {
+	elements := []interface{}{
+		CardText(summary),
+		map[string]interface{}{
+			"tag": "action",
+			"actions": []interface{}{
+				cardButton("提交 / Submit", "primary", CardActionSubmit),
+				cardButton("编辑 / Edit", "default", CardActionEdit),
+				cardButton("取消 / Cancel", "danger", CardActionCancel),
+			},
+		},
+	}
+	if autoSubmit > 0 {
+		minutes := int(autoSubmit.Round(time.Minute) / time.Minute)
+		elements = append(elements, CardNote(fmt.Sprintf(
+			"%d 分钟内未操作将自动提交 / Will be submitted automatically in %d min", minutes, minutes)))
+	}
+	return BuildCard("📋 请确认提交 / Please confirm your report", CardTemplateBlue, elements)
+}
+
+// BuildConfirmResultCard 构建确认卡片处理后的状态卡片（不含按钮，避免重复操作）。
+func BuildConfirmResultCard(title, template, summary string) map[string]interface{} {
+	var elements []interface{}
+	if summary != "" {
+		elements = append(elements, CardText(summary))
+	}
+	return BuildCard(title, template, elements)
+}
+
+// buildCard 构建卡片骨架（开启 update_multi 以便后续更新卡片内容）。
+func BuildCard(title, template string, elements []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"config": map[string]interface{}{
+			"wide_screen_mode": true,
+			"update_multi":     true,
+		},
+		"header": map[string]interface{}{
+			"title": map[string]interface{}{
+				"tag":     "plain_text",
+				"content": title,
+			},
+			"template": template,
+		},
+		"elements": elements,
+	}
+}
+
+// cardText 构建纯文本段落（不解析 Markdown，避免用户输入中的符号被误渲染）。
+func CardText(content string) map[string]interface{} {
+	return map[string]interface{}{
+		"tag": "div",
+		"text": map[string]interface{}{
+			"tag":     "plain_text",
+			"content": content,
+		},
+	}
+}
+
+// cardNote 构建备注（灰色小字）。
+func CardNote(content string) map[string]interface{} {
+	return map[string]interface{}{
+		"tag": "note",
+		"elements": []interface{}{
+			map[string]interface{}{
+				"tag":     "plain_text",
+				"content": content,
+			},
+		},
+	}
+}
+
+// CardForm 构建表单容器，表单内的提交按钮回调时携带全部组件的 form_value（组件名 → 值）。
+func CardForm(name string, elements []interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"tag":      "form",
+		"name":     name,
+		"elements": elements,
+	}
+}
+
+// CardFormSubmit 构建表单提交按钮，点击后回调 value 为 {"action": action} 加上 extra 中的键值。
+func CardFormSubmit(text, action string, extra map[string]interface{}) map[string]interface{} {
+	value := map[string]interface{}{CardActionKey: action}
+	for k, v := range extra {
+		value[k] = v
+	}
+	return map[string]interface{}{
+		"tag":         "button",
+		"name":        action,
+		"action_type": "form_submit",
+		"type":        "primary",
+		"text": map[string]interface{}{
+			"tag":     "plain_text",
+			"content": text,
+		},
+		"value": value,
+	}
+}
+
+// CardInput 构建表单输入框，defaultValue 非空时预填。
+func CardInput(name, placeholder, defaultValue string) map[string]interface{} {
+	input := map[string]interface{}{
+		"tag":  "input",
+		"name": name,
+		"placeholder": map[string]interface{}{
+			"tag":     "plain_text",
+			"content": placeholder,
+		},
+	}
+	if defaultValue != "" {
+		input["default_value"] = defaultValue
+	}
+	return input
+}
+
+// CardOption 构建下拉框选项。
+func CardOption(text, value string) map[string]interface{} {
+	return map[string]interface{}{
+		"text": map[string]interface{}{
+			"tag":     "plain_text",
+			"content": text,
+		},
+		"value": value,
+	}
+}
+
+// CardSelect 构建表单下拉框，initial 非空时预选。
+func CardSelect(name, placeholder string, options []interface{}, initial string) map[string]interface{} {
+	sel := map[string]interface{}{
+		"tag":  "select_static",
+		"name": name,
+		"placeholder": map[string]interface{}{
+			"tag":     "plain_text",
+			"content": placeholder,
+		},
+		"options": options,
+	}
+	if initial != "" {
+		sel["initial_option"] = initial
+	}
+	return sel
+}
+
+// cardButton 构建回调按钮，点击后回调 value 为 {"action": action}。
+func cardButton(text, buttonType, action string) map[string]interface{} {
+	return map[string]interface{}{
+		"tag": "button",
+		"text": map[string]interface{}{
+			"tag":     "plain_text",
+			"content": text,
+		},
+		"type":  buttonType,
+		"value": map[string]interface{}{CardActionKey: action},
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"log"
 	"strings"
+	"sync"
 
 	lark "github.com/larksuite/oapi-sdk-go/v3"
 	larkcore "github.com/larksuite/oapi-sdk-go/v3/core"
@@ -22,6 +23,9 @@ type Client struct {
 	larkCli   *lark.Client
 	appID     string
 	appSecret string
+
+	botMu     sync.Mutex
+	botOpenID string // 机器人 open_id（BotOpenID 缓存）
 }
 
 // NewClient 创建新的飞书客户端。
@@ -51,13 +55,23 @@ func (c *Client) Start(ctx context.Context) error {
 // SendTextMessage 发送文本消息到指定聊天。
 func (c *Client) SendTextMessage(ctx context.Context, chatID, text string) error {
 	log.Printf("[Feishu] SendTextMessage: chatID=%s, text=%q", chatID, truncate(text, 100))
+	return c.sendText(ctx, larkim.ReceiveIdTypeChatId, chatID, text)
+}
 
+// SendTextMessageToUser 按用户 open_id 发送文本消息（用于只有用户 ID 的事件，如机器人菜单）。
+func (c *Client) SendTextMessageToUser(ctx context.Context, openID, text string) error {
+	log.Printf("[Feishu] SendTextMessageToUser: openID=%s, text=%q", openID, truncate(text, 100))
+	return c.sendText(ctx, larkim.ReceiveIdTypeOpenId, openID, text)
+}
+
+// sendText 向指定类型的接收者发送文本消息。
+func (c *Client) sendText(ctx context.Context, receiveIDType, receiveID, text string) error {
 	content := fmt.Sprintf(`{"text":"%s"}`, escapeJSON(text))
 
 	req := larkim.NewCreateMessageReqBuilder().
-		ReceiveIdType(larkim.ReceiveIdTypeChatId).
+		ReceiveIdType(receiveIDType).
 		Body(larkim.NewCreateMessageReqBodyBuilder().
-			ReceiveId(chatID).
+			ReceiveId(receiveID).
 			MsgType(larkim.MsgTypeText).
 			Content(content).
 			Build()).
@@ -195,51 +209,68 @@ func (c *Client) ForwardMessage(ctx context.Context, messageID, targetChatID str
 	return nil
 }
 
+// PostElement 是富文本（post）消息段落中的一个元素。
+type PostElement map[string]interface{}
+
+// PostText 返回富文本中的文本元素。
+func PostText(text string) PostElement {
+	return PostElement{"tag": "text", "text": text}
+}
+
+// PostAt 返回富文本中 @用户 的元素。
+func PostAt(openID string) PostElement {
+	return PostElement{"tag": "at", "user_id": openID}
+}
+
+// PostAts 返回依次 @多个用户的段落（以空格分隔）。
+func PostAts(openIDs []string) []PostElement {
+	elems := make([]PostElement, 0, 2*len(openIDs))
+	for i, id := range openIDs {
+		if i > 0 {
+			elems = append(elems, PostText(" "))
+		}
+		elems = append(elems, PostAt(id))
+	}
+	return elems
+}
+
 // SendPostMessage 发送富文本（post）消息到指定聊天，返回消息ID（用于话题内回复）。
-// atUserID 可选，如果不为空则在消息中 @该用户。
-func (c *Client) SendPostMessage(ctx context.Context, chatID, title, textContent, atUserOpenID string) (string, error) {
-	log.Printf("[Feishu] SendPostMessage: chatID=%s, title=%s, atUser=%s", chatID, title, atUserOpenID)
+// atUserOpenIDs 可选，不为空时在消息开头 @这些用户。uuid 可选，相同 uuid 的请求 1 小时内至多发送一条消息。
+func (c *Client) SendPostMessage(ctx context.Context, chatID, title, textContent string, atUserOpenIDs []string, uuid string) (string, error) {
+	log.Printf("[Feishu] SendPostMessage: chatID=%s, title=%s, atUsers=%v", chatID, title, atUserOpenIDs)
 
 	// 构建富文本内容段落
-	var contentParagraphs [][]map[string]interface{}
+	var contentParagraphs [][]PostElement
 
 	// 第一段：@用户（如果有的话）
-	if atUserOpenID != "" {
-		contentParagraphs = append(contentParagraphs, []map[string]interface{}{
-			{
-				"tag":     "at",
-				"user_id": atUserOpenID,
-			},
-		})
+	if len(atUserOpenIDs) > 0 {
+		contentParagraphs = append(contentParagraphs, PostAts(atUserOpenIDs))
 	}
 
 	// 第二段：正文
-	contentParagraphs = append(contentParagraphs, []map[string]interface{}{
-		{
-			"tag":  "text",
-			"text": textContent,
-		},
-	})
+	contentParagraphs = append(contentParagraphs, []PostElement{PostText(textContent)})
 
-	postContent := map[string]interface{}{
-		"zh_cn": map[string]interface{}{
-			"title":   title,
-			"content": contentParagraphs,
-		},
-	}
+	return c.SendPostParagraphs(ctx, chatID, title, contentParagraphs, uuid)
+}
 
-	contentBytes, err := json.Marshal(postContent)
+// SendPostParagraphs 发送由段落构成的富文本消息到指定聊天（段落由 PostText / PostAt 构建），返回消息ID。
+// uuid 可选，相同 uuid 的请求 1 小时内至多发送一条消息。
+func (c *Client) SendPostParagraphs(ctx context.Context, chatID, title string, paragraphs [][]PostElement, uuid string) (string, error) {
+	content, err := postContent(title, paragraphs)
 	if err != nil {
-		return "", fmt.Errorf("failed to marshal post content: %w", err)
+		return "", err
 	}
 
+	body := larkim.NewCreateMessageReqBodyBuilder().
+		ReceiveId(chatID).
+		MsgType(larkim.MsgTypePost).
+		Content(content)
+	if uuid != "" {
+		body.Uuid(uuid)
+	}
 	req := larkim.NewCreateMessageReqBuilder().
 		ReceiveIdType(larkim.ReceiveIdTypeChatId).
-		Body(larkim.NewCreateMessageReqBodyBuilder().
-			ReceiveId(chatID).
-			MsgType(larkim.MsgTypePost).
-			Content(string(contentBytes)).
-			Build()).
+		Body(body.Build()).
 		Build()
 
 	resp, err := c.larkCli.Im.Message.Create(ctx, req)
@@ -261,6 +292,82 @@ func (c *Client) SendPostMessage(ctx context.Context, chatID, title, textContent
 	return msgID, nil
 }
 
+// UpdatePostMessage 编辑已发送的富文本消息（如工单话题根消息的状态）。
+func (c *Client) UpdatePostMessage(ctx context.Context, messageID, title string, paragraphs [][]PostElement) error {
+	log.Printf("[Feishu] UpdatePostMessage: msgID=%s, title=%s", messageID, title)
+
+	content, err := postContent(title, paragraphs)
+	if err != nil {
+		return err
+	}
+
+	req := larkim.NewUpdateMessageReqBuilder().
+		MessageId(messageID).
+		Body(larkim.NewUpdateMessageReqBodyBuilder().
+			MsgType(larkim.MsgTypePost).
+			Content(content).
+			Build()).
+		Build()
+
+	resp, err := c.larkCli.Im.Message.Update(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to update post message: %w", err)
+	}
+
+	if !resp.Success() {
+		return fmt.Errorf("update post failed: code=%d, msg=%s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// postContent 构建富文本消息的 content JSON。
+func postContent(title string, paragraphs [][]PostElement) (string, error) {
+	postContent := map[string]interface{}{
+		"zh_cn": map[string]interface{}{
+			"title":   title,
+			"content": paragraphs,
+		},
+	}
+
+	contentBytes, err := json.Marshal(postContent)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal post content: %w", err)
+	}
+	return string(contentBytes), nil
+}
+
+// BotOpenID 返回机器人自身的 open_id（首次调用时查询并缓存），用于识别 @机器人 的消息。
+func (c *Client) BotOpenID(ctx context.Context) (string, error) {
+	c.botMu.Lock()
+	defer c.botMu.Unlock()
+	if c.botOpenID != "" {
+		return c.botOpenID, nil
+	}
+
+	resp, err := c.larkCli.Get(ctx, "/open-apis/bot/v3/info", nil, larkcore.AccessTokenTypeTenant)
+	if err != nil {
+		return "", fmt.Errorf("failed to get bot info: %w", err)
+	}
+
+	var info struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+		Bot  struct {
+			OpenID string `json:"open_id"`
+		} `json:"bot"`
+	}
+	if err := json.Unmarshal(resp.RawBody, &info); err != nil {
+		return "", fmt.Errorf("failed to parse bot info: %w", err)
+	}
+	if info.Code != 0 || info.Bot.OpenID == "" {
+		return "", fmt.Errorf("get bot info failed: code=%d, msg=%s", info.Code, info.Msg)
+	}
+
+	c.botOpenID = info.Bot.OpenID
+	log.Printf("[Feishu] Bot open_id=%s", c.botOpenID)
+	return c.botOpenID, nil
+}
+
 // InviteUserToChat 邀请用户加入群聊。
 func (c *Client) InviteUserToChat(ctx context.Context, chatID, userOpenID string) error {
 	log.Printf("[Feishu] InviteUserToChat: chatID=%s, user=%s", chatID, userOpenID)
@@ -286,21 +393,138 @@ func (c *Client) InviteUserToChat(ctx context.Context, chatID, userOpenID string
 	return nil
 }
 
-// ReplyFileInThread 在话题内回复文件（将文件放入与摘要同一话题中）。
-func (c *Client) ReplyFileInThread(ctx context.Context, parentMsgID, fileKey string) error {
-	log.Printf("[Feishu] ReplyFileInThread: parentMsg=%s, fileKey=%s", parentMsgID, fileKey)
+// PinMessage 在群内置顶消息（Pin）。
+func (c *Client) PinMessage(ctx context.Context, messageID string) error {
+	log.Printf("[Feishu] PinMessage: messageID=%s", messageID)
 
-	content := fmt.Sprintf(`{"file_key":"%s"}`, fileKey)
+	req := larkim.NewCreatePinReqBuilder().
+		Body(larkim.NewCreatePinReqBodyBuilder().
+			MessageId(messageID).
+			Build()).
+		Build()
+
+	resp, err := c.larkCli.Im.Pin.Create(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to pin message: %w", err)
+	}
+
+	if !resp.Success() {
+		return fmt.Errorf("pin message failed: code=%d, msg=%s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// SendCardMessage 发送交互式卡片消息到指定聊天，返回消息ID（用于后续更新卡片）。
+func (c *Client) SendCardMessage(ctx context.Context, chatID string, card map[string]interface{}) (string, error) {
+	log.Printf("[Feishu] SendCardMessage: chatID=%s", chatID)
+
+	contentBytes, err := json.Marshal(card)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal card: %w", err)
+	}
+
+	req := larkim.NewCreateMessageReqBuilder().
+		ReceiveIdType(larkim.ReceiveIdTypeChatId).
+		Body(larkim.NewCreateMessageReqBodyBuilder().
+			ReceiveId(chatID).
+			MsgType(larkim.MsgTypeInteractive).
+			Content(string(contentBytes)).
+			Build()).
+		Build()
+
+	resp, err := c.larkCli.Im.Message.Create(ctx, req)
+	if err != nil {
+		return "", fmt.Errorf("failed to send card message: %w", err)
+	}
+
+	if !resp.Success() {
+		return "", fmt.Errorf("send card failed: code=%d, msg=%s", resp.Code, resp.Msg)
+	}
+
+	msgID := ""
+	if resp.Data != nil && resp.Data.MessageId != nil {
+		msgID = *resp.Data.MessageId
+	}
+
+	log.Printf("[Feishu] Card message sent successfully, msgID=%s", msgID)
+	return msgID, nil
+}
+
+// UpdateCardMessage 更新已发送的卡片消息内容（卡片需开启 update_multi）。
+func (c *Client) UpdateCardMessage(ctx context.Context, messageID string, card map[string]interface{}) error {
+	log.Printf("[Feishu] UpdateCardMessage: msgID=%s", messageID)
+
+	contentBytes, err := json.Marshal(card)
+	if err != nil {
+		return fmt.Errorf("failed to marshal card: %w", err)
+	}
+
+	req := larkim.NewPatchMessageReqBuilder().
+		MessageId(messageID).
+		Body(larkim.NewPatchMessageReqBodyBuilder().
+			Content(string(contentBytes)).
+			Build()).
+		Build()
+
+	resp, err := c.larkCli.Im.Message.Patch(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to update card message: %w", err)
+	}
+
+	if !resp.Success() {
+		return fmt.Errorf("update card failed: code=%d, msg=%s", resp.Code, resp.Msg)
+	}
+
+	return nil
+}
+
+// ReplyTextInThread 在话题内回复文本消息。
+func (c *Client) ReplyTextInThread(ctx context.Context, parentMsgID, text string) error {
+	log.Printf("[Feishu] ReplyTextInThread: parentMsg=%s, text=%q", parentMsgID, truncate(text, 100))
+
+	content := larkim.NewMessageTextBuilder().
+		Text(text).
+		Build()
 
 	req := larkim.NewReplyMessageReqBuilder().
 		MessageId(parentMsgID).
 		Body(larkim.NewReplyMessageReqBodyBuilder().
 			Content(content).
-			MsgType(larkim.MsgTypeFile).
+			MsgType(larkim.MsgTypeText).
 			ReplyInThread(true).
 			Build()).
 		Build()
 
+	resp, err := c.larkCli.Im.Message.Reply(ctx, req)
+	if err != nil {
+		return fmt.Errorf("failed to reply text in thread: %w", err)
+	}
+
+	if !resp.Success() {
+		return fmt.Errorf("reply text in thread failed: code=%d, msg=%s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// ReplyFileInThread 在话题内回复文件（将文件放入与摘要同一话题中）。
+// uuid 可选，相同 uuid 的请求 1 小时内至多回复一次。
+func (c *Client) ReplyFileInThread(ctx context.Context, parentMsgID, fileKey, uuid string) error {
+	log.Printf("[Feishu] ReplyFileInThread: parentMsg=%s, fileKey=%s", parentMsgID, fileKey)
+
+	content := fmt.Sprintf(`{"file_key":"%s"}`, fileKey)
+
+	body := larkim.NewReplyMessageReqBodyBuilder().
+		Content(content).
+		MsgType(larkim.MsgTypeFile).
+		ReplyInThread(true)
+	if uuid != "" {
+		body.Uuid(uuid)
+	}
+	req := larkim.NewReplyMessageReqBuilder().
+		MessageId(parentMsgID).
+		Body(body.Build()).
+		Build()
+
 	resp, err := c.larkCli.Im.Message.Reply(ctx, req)
 	if err != nil {
 		return fmt.Errorf("failed to reply file in thread: %w", err)
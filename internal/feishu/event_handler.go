@@ -5,10 +5,13 @@ import (
 	"context"
 	"encoding/json"
 	"log"
+	"strings"
 	"sync"
 
 	"github.com/even/feishu-bot/internal/conversation"
 	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher"
+	"github.com/larksuite/oapi-sdk-go/v3/event/dispatcher/callback"
+	larkapplication "github.com/larksuite/oapi-sdk-go/v3/service/application/v6"
 	larkim "github.com/larksuite/oapi-sdk-go/v3/service/im/v1"
 )
 
@@ -16,6 +19,68 @@ import (
 type MessageHandler interface {
 	HandleMessage(ctx context.Context, chatID, senderID, messageID, content, msgType, fileKey string) error
 	HandleEscalation(ctx context.Context, chatID, senderID, content string) error
+	// HandleCardAction 处理卡片按钮回调，需在 3 秒内返回；耗时操作应异步执行。
+	HandleCardAction(ctx context.Context, action CardAction) (*CardActionResult, error)
+	// HandleThreadMessage 处理群聊话题内的回复消息（如技术支持在工单话题内的回复）。
+	HandleThreadMessage(ctx context.Context, msg ThreadMessage) error
+	// HandleGroupCommand 处理群聊中不在话题内、@机器人 的消息（如查看值班人员）。
+	HandleGroupCommand(ctx context.Context, msg ThreadMessage) error
+	// HandleBotMenu 处理用户点击机器人自定义菜单（事件只携带用户 open_id 和菜单 event_key）。
+	HandleBotMenu(ctx context.Context, openID, eventKey string) error
+	// HandleReaction 处理用户对消息添加的表情回复（如在工单根消息上标记已解决）。
+	HandleReaction(ctx context.Context, messageID, operatorID, emojiType string) error
+}
+
+// ThreadMessage 是群聊话题内的回复消息（群命令也使用此结构，RootID 为空）。
+type ThreadMessage struct {
+	ChatID    string    // 群聊 chatID
+	RootID    string    // 话题根消息 ID（不在话题内时为空）
+	MessageID string    // 消息 ID
+	SenderID  string    // 发送者 open_id
+	Content   string    // 文本内容（@ 占位符已替换为 @姓名）
+	MsgType   string    // 消息类型
+	FileKey   string    // 文件消息的 fileKey
+	Mentions  []Mention // 消息中 @ 的用户（不含机器人）
+	// MentionsBot 表示消息 @了机器人（技术支持命令），此时 Content 中已去掉 @机器人
+	MentionsBot bool
+}
+
+// Mention 是消息中被 @ 的用户。
+type Mention struct {
+	OpenID string
+	Name   string
+}
+
+// CardAction 是卡片按钮回调的内容。
+type CardAction struct {
+	ChatID     string                 // 卡片所在会话
+	OperatorID string                 // 点击者 open_id
+	MessageID  string                 // 卡片消息 ID
+	Value      map[string]interface{} // 按钮 value
+	FormValue  map[string]interface{} // 表单提交值（组件 name → 值），仅表单提交按钮有
+}
+
+// Action 返回按钮 value 中的动作标识（CardActionKey）。
+func (a CardAction) Action() string {
+	action, _ := a.Value[CardActionKey].(string)
+	return action
+}
+
+// FormStrings 返回表单中的字符串值（输入框、下拉框），忽略其他类型。
+func (a CardAction) FormStrings() map[string]string {
+	values := make(map[string]string, len(a.FormValue))
+	for name, v := range a.FormValue {
+		if s, ok := v.(string); ok {
+			values[name] = s
+		}
+	}
+	return values
+}
+
+// CardActionResult 是卡片回调的即时响应。
+type CardActionResult struct {
+	Toast string                 // Toast 提示文本（可选）
+	Card  map[string]interface{} // 替换后的卡片内容（可选）
 }
 
 // EventHandlers 保存事件处理器。
@@ -44,7 +109,100 @@ func (e *EventHandlers) SetFeishuClient(client *Client) {
 func (e *EventHandlers) RegisterHandlers() *dispatcher.EventDispatcher {
 	return dispatcher.NewEventDispatcher("", "").
 		OnP2MessageReceiveV1(e.handlePrivateMessage).
-		OnP1P2PChatCreatedV1(e.handleP2PChatCreated)
+		OnP1P2PChatCreatedV1(e.handleP2PChatCreated).
+		OnP2BotMenuV6(e.handleBotMenu).
+		OnP2MessageReactionCreatedV1(e.handleReaction).
+		OnP2CardActionTrigger(e.handleCardAction)
+}
+
+// handleReaction 处理消息表情回复事件（只处理用户添加的表情）。
+func (e *EventHandlers) handleReaction(ctx context.Context, event *larkim.P2MessageReactionCreatedV1) error {
+	data := event.Event
+	if data == nil || data.MessageId == nil || data.ReactionType == nil || data.ReactionType.EmojiType == nil ||
+		data.UserId == nil || data.UserId.OpenId == nil {
+		return nil
+	}
+	if data.OperatorType != nil && *data.OperatorType != "user" {
+		return nil
+	}
+
+	if event.EventV2Base != nil && event.EventV2Base.Header != nil && event.EventV2Base.Header.EventID != "" && e.store != nil {
+		isNew, err := e.store.TryMarkMessageProcessed(ctx, event.EventV2Base.Header.EventID)
+		if err != nil || !isNew {
+			return nil
+		}
+	}
+	log.Printf("[Event] Reaction %s on message %s by %s", *data.ReactionType.EmojiType, *data.MessageId, *data.UserId.OpenId)
+
+	if err := e.messageHandler.HandleReaction(ctx, *data.MessageId, *data.UserId.OpenId, *data.ReactionType.EmojiType); err != nil {
+		log.Printf("[ERROR] HandleReaction failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// handleBotMenu 处理机器人自定义菜单点击事件。
+func (e *EventHandlers) handleBotMenu(ctx context.Context, event *larkapplication.P2BotMenuV6) error {
+	if event.Event == nil || event.Event.EventKey == nil || event.Event.Operator == nil ||
+		event.Event.Operator.OperatorId == nil || event.Event.Operator.OperatorId.OpenId == nil {
+		return nil
+	}
+	openID := *event.Event.Operator.OperatorId.OpenId
+	eventKey := *event.Event.EventKey
+
+	// 与消息共用去重：WebSocket 重发的同一事件只处理一次
+	if event.EventV2Base != nil && event.EventV2Base.Header != nil && event.EventV2Base.Header.EventID != "" && e.store != nil {
+		isNew, err := e.store.TryMarkMessageProcessed(ctx, event.EventV2Base.Header.EventID)
+		if err != nil || !isNew {
+			return nil
+		}
+	}
+	log.Printf("[Event] Bot menu clicked: openID=%s, eventKey=%s", openID, eventKey)
+
+	if err := e.messageHandler.HandleBotMenu(ctx, openID, eventKey); err != nil {
+		log.Printf("[ERROR] HandleBotMenu failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// handleCardAction 处理卡片按钮回调事件。
+func (e *EventHandlers) handleCardAction(ctx context.Context, event *callback.CardActionTriggerEvent) (*callback.CardActionTriggerResponse, error) {
+	if event.Event == nil || event.Event.Action == nil {
+		return nil, nil
+	}
+
+	action := CardAction{
+		Value:     event.Event.Action.Value,
+		FormValue: event.Event.Action.FormValue,
+	}
+	if event.Event.Operator != nil {
+		action.OperatorID = event.Event.Operator.OpenID
+	}
+	if event.Event.Context != nil {
+		action.ChatID = event.Event.Context.OpenChatID
+		action.MessageID = event.Event.Context.OpenMessageID
+	}
+	log.Printf("[Event] Card action: chatID=%s, operator=%s, msgID=%s, action=%s",
+		action.ChatID, action.OperatorID, action.MessageID, action.Action())
+
+	result, err := e.messageHandler.HandleCardAction(ctx, action)
+	if err != nil {
+		log.Printf("[ERROR] HandleCardAction failed: %v", err)
+		return nil, err
+	}
+	if result == nil {
+		return nil, nil
+	}
+
+	resp := &callback.CardActionTriggerResponse{}
+	if result.Toast != "" {
+		resp.Toast = &callback.Toast{Type: "info", Content: result.Toast}
+	}
+	if result.Card != nil {
+		resp.Card = &callback.Card{Type: "raw", Data: result.Card}
+	}
+	return resp, nil
 }
 
 // handleP2PChatCreated 处理用户首次打开机器人对话事件。
@@ -61,24 +219,7 @@ func (e *EventHandlers) handleP2PChatCreated(ctx context.Context, event *larkim.
 
 	// 发送欢迎消息
 	if chatID != "" && e.feishuClient != nil {
-		welcome := "您好，我是技术支持助手。/ Hi, I'm the tech support assistant.\n\n" +
-			"📋 反馈问题，请提供以下信息 / To report an issue, please provide:\n" +
-			"  - 问题描述 / Issue Description\n" +
-			"  - 发生时间 / Time of Occurrence\n" +
-			"  - 是否必现 / Reproducible?\n" +
-			"  - 应用版本 / App Version\n" +
-			"  - 眼镜版本 / Glasses Firmware\n" +
-			"  - 眼镜SN号 / Glasses SN\n" +
-			"  - 戒指版本 / Ring Firmware\n" +
-			"  - 戒指SN号 / Ring SN\n" +
-			"  - 手机型号 / Phone Model\n" +
-			"  - 手机系统版本 / Phone OS Version\n" +
-			"  - 是否使用VPN / Using VPN?（可选 / optional）\n\n" +
-			"💡 反馈建议，请直接发送 / To submit a suggestion, send:\n" +
-			"  反馈：您的内容 / feedback: your content\n" +
-			"  建议：您的内容 / suggestion: your content\n\n" +
-			"您可以一次性告诉我，也可以分多次发送。\nYou can provide all info at once or send it in multiple messages.\n" +
-			"如有日志文件，可直接发送附件。\nIf you have log files, feel free to send them as attachments."
+		welcome := conversation.WelcomeMessage()
 		if err := e.feishuClient.SendTextMessage(ctx, chatID, welcome); err != nil {
 			log.Printf("[Event] Failed to send welcome message: %v", err)
 		}
@@ -93,6 +234,14 @@ func (e *EventHandlers) getChatLock(chatID string) *sync.Mutex {
 	return val.(*sync.Mutex)
 }
 
+// LockChat 锁定指定会话（与消息处理共用同一把锁），返回解锁函数。
+// 供卡片回调、定时任务等消息事件之外的入口串行访问会话。
+func (e *EventHandlers) LockChat(chatID string) (unlock func()) {
+	mu := e.getChatLock(chatID)
+	mu.Lock()
+	return mu.Unlock
+}
+
 // handlePrivateMessage 处理私聊（P2P）消息事件。
 func (e *EventHandlers) handlePrivateMessage(ctx context.Context, event *larkim.P2MessageReceiveV1) error {
 	// 提取基本信息
@@ -135,6 +284,14 @@ func (e *EventHandlers) handlePrivateMessage(ctx context.Context, event *larkim.
 		log.Printf("[DEDUP] Message %s is new, processing", messageID)
 	}
 
+	// 群聊话题内的回复交给话题处理（工单话题双向转发），其他 @机器人 的群消息作为群命令
+	if chatType == "group" || chatType == "topic_group" {
+		if event.Event.Message.RootId != nil && *event.Event.Message.RootId != "" {
+			return e.handleThreadMessage(ctx, event, senderID)
+		}
+		return e.handleGroupMessage(ctx, event, senderID)
+	}
+
 	// 只处理私聊消息
 	if chatType != "p2p" {
 		log.Printf("[Event] Ignoring non-p2p message (chatType=%s)", chatType)
@@ -162,6 +319,103 @@ func (e *EventHandlers) handlePrivateMessage(ctx context.Context, event *larkim.
 	return nil
 }
 
+// handleThreadMessage 处理群聊话题内的回复消息（同一话题串行处理）。
+func (e *EventHandlers) handleThreadMessage(ctx context.Context, event *larkim.P2MessageReceiveV1, senderID string) error {
+	if event.Event.Sender.SenderType != nil && *event.Event.Sender.SenderType != "user" {
+		return nil
+	}
+	msg := e.buildThreadMessage(ctx, event, senderID)
+
+	log.Printf("[Event] Thread message: chatID=%s, root=%s, sender=%s, msgID=%s, type=%s",
+		msg.ChatID, msg.RootID, msg.SenderID, msg.MessageID, msg.MsgType)
+
+	mu := e.getChatLock(msg.RootID)
+	mu.Lock()
+	defer mu.Unlock()
+
+	if err := e.messageHandler.HandleThreadMessage(ctx, msg); err != nil {
+		log.Printf("[ERROR] HandleThreadMessage failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// handleGroupMessage 处理群聊中不在话题内的消息：只处理 @机器人 的群命令，其他消息忽略。
+func (e *EventHandlers) handleGroupMessage(ctx context.Context, event *larkim.P2MessageReceiveV1, senderID string) error {
+	if event.Event.Sender.SenderType != nil && *event.Event.Sender.SenderType != "user" {
+		return nil
+	}
+	if len(event.Event.Message.Mentions) == 0 {
+		return nil
+	}
+	msg := e.buildThreadMessage(ctx, event, senderID)
+	if !msg.MentionsBot {
+		return nil
+	}
+
+	log.Printf("[Event] Group command: chatID=%s, sender=%s, msgID=%s, content=%q",
+		msg.ChatID, msg.SenderID, msg.MessageID, msg.Content)
+
+	if err := e.messageHandler.HandleGroupCommand(ctx, msg); err != nil {
+		log.Printf("[ERROR] HandleGroupCommand failed: %v", err)
+		return err
+	}
+	return nil
+}
+
+// buildThreadMessage 从群消息事件构建 ThreadMessage：提取内容，将 @ 占位符替换为 @姓名（@机器人 去掉）。
+// 不在话题内的消息 RootID 为空。
+func (e *EventHandlers) buildThreadMessage(ctx context.Context, event *larkim.P2MessageReceiveV1, senderID string) ThreadMessage {
+	msg := ThreadMessage{SenderID: senderID}
+	if event.Event.Message.RootId != nil {
+		msg.RootID = *event.Event.Message.RootId
+	}
+	if event.Event.Message.ChatId != nil {
+		msg.ChatID = *event.Event.Message.ChatId
+	}
+	if event.Event.Message.MessageId != nil {
+		msg.MessageID = *event.Event.Message.MessageId
+	}
+	if event.Event.Message.MessageType != nil {
+		msg.MsgType = *event.Event.Message.MessageType
+	}
+	msg.Content, msg.FileKey = e.extractMessageInfo(event)
+
+	// 将 @_user_1 占位符替换为 @姓名；@机器人 的占位符直接去掉
+	botID := ""
+	if len(event.Event.Message.Mentions) > 0 && e.feishuClient != nil {
+		id, err := e.feishuClient.BotOpenID(ctx)
+		if err != nil {
+			log.Printf("[Event] Failed to get bot open_id: %v", err)
+		}
+		botID = id
+	}
+	for _, m := range event.Event.Message.Mentions {
+		if m == nil {
+			continue
+		}
+		mention := Mention{}
+		if m.Name != nil {
+			mention.Name = *m.Name
+		}
+		if m.Id != nil && m.Id.OpenId != nil {
+			mention.OpenID = *m.Id.OpenId
+		}
+		replacement := "@" + mention.Name
+		if botID != "" && mention.OpenID == botID {
+			msg.MentionsBot = true
+			replacement = ""
+		} else {
+			msg.Mentions = append(msg.Mentions, mention)
+		}
+		if m.Key != nil {
+			msg.Content = strings.ReplaceAll(msg.Content, *m.Key, replacement)
+		}
+	}
+	msg.Content = strings.TrimSpace(msg.Content)
+	return msg
+}
+
 // extractMessageInfo 从消息中提取文本内容和文件信息。
 func (e *EventHandlers) extractMessageInfo(event *larkim.P2MessageReceiveV1) (content string, fileKey string) {
 	msg := event.Event.Message
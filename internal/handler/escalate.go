@@ -4,78 +4,342 @@ package handler
 import (
 	"bytes"
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
 	"log"
+	"time"
 
+	"github.com/even/feishu-bot/internal/assign"
 	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/internal/oncall"
+	"github.com/even/feishu-bot/internal/ticket"
+	"github.com/even/feishu-bot/internal/workhours"
 	"github.com/even/feishu-bot/pkg/models"
 )
 
 // EscalationHandler 处理转人工服务。
 type EscalationHandler struct {
 	feishuClient      *feishu.Client
-	escalationGroupID string
+	escalationGroupID string                   // 默认转人工群组
+	routes            []models.EscalationRoute // 路由规则（按顺序匹配）
+	oncall            *oncall.Schedule         // 值班排班（摘要中 @当前值班人员）
+	critical          models.CriticalActions   // 紧急问题的额外处理（@负责人、置顶摘要）
+	hours             *workhours.Calendar      // 工作时间（非工作时间提示、暂缓非紧急问题）
+	assigner          *assign.Assigner         // 自动分配负责人
+	tickets           *ticket.Store
 }
 
 // NewEscalationHandler 创建新的转人工处理器。
-func NewEscalationHandler(client *feishu.Client, escalationGroupID string) *EscalationHandler {
+func NewEscalationHandler(client *feishu.Client, escalationGroupID string, routes []models.EscalationRoute, schedule *oncall.Schedule, critical models.CriticalActions, hours *workhours.Calendar, assigner *assign.Assigner, tickets *ticket.Store) *EscalationHandler {
 	return &EscalationHandler{
 		feishuClient:      client,
 		escalationGroupID: escalationGroupID,
+		routes:            routes,
+		oncall:            schedule,
+		critical:          critical,
+		hours:             hours,
+		assigner:          assigner,
+		tickets:           tickets,
 	}
 }
 
-// HandleEscalation 处理转人工请求：邀请用户入群 → 发送摘要（@用户）→ 话题内回复文件 → 通知用户。
-func (h *EscalationHandler) HandleEscalation(ctx context.Context, conv *models.Conversation) error {
-	log.Printf("[Escalation] Processing for chat %s, user %s", conv.ChatID, conv.SenderID)
+// 转人工任务的重试策略。
+const (
+	// escalationLease 是任务执行中的租约：进程在执行中退出时，租约到期后由后台任务继续执行。
+	escalationLease = 5 * time.Minute
+	// escalationRetryBase 是首次重试的退避时间，之后每次翻倍。
+	escalationRetryBase = 15 * time.Second
+	// escalationRetryMax 是退避时间上限。
+	escalationRetryMax = 30 * time.Minute
+	// escalationMaxAttempts 是任务最多执行的次数，用尽后标记为失败。
+	escalationMaxAttempts = 10
+)
+
+// ErrEscalationHeld 表示任务在非工作时间暂缓，已安排在上班时间继续执行。
+var ErrEscalationHeld = errors.New("escalation held until business hours")
+
+// HandleEscalation 执行（或继续执行）转人工任务中未完成的步骤：选择路由 → 邀请用户入群 → 生成工单号 →
+// 分配负责人 → 发送摘要（@用户，显示负责人）→ 置顶紧急问题 → 保存工单 → 话题内回复文件 → 通知用户。
+// 每步完成后保存任务进度，已完成的步骤不会重复执行（摘要和文件带 uuid 发送，中途退出后重试也不会重复）。
+// 非工作时间提交的非紧急问题（business_hours.hold）在生成工单号后暂缓，返回 ErrEscalationHeld。
+// 摘要发出后返回工单；仍有未完成的步骤时同时返回错误，任务已按退避时间安排重试
+// （次数用尽时标记为 EscalationFailed）。
+func (h *EscalationHandler) HandleEscalation(ctx context.Context, job *models.EscalationJob) (*models.Ticket, error) {
+	conv := job.Conversation
+	log.Printf("[Escalation] Processing job %s for chat %s, user %s (attempt %d)", job.ID, conv.ChatID, conv.SenderID, job.Attempts+1)
+
+	// 执行期间持有租约，进程退出后由后台任务继续
+	job.Status = models.EscalationPending
+	job.NextAttemptAt = time.Now().Add(escalationLease)
+	h.saveJob(ctx, job)
+
+	// 0. 按路由规则选择目标群组（只选择一次，重试时发往同一群组）
+	if job.Target == nil {
+		target := h.Route(conv)
+		if job.Severity == models.SeverityCritical {
+			target.Mentions = append(target.Mentions, h.critical.Mentions...)
+		}
+		job.Target = &target
+		log.Printf("[Escalation] Job %s routed to group %s (route=%q, oncall=%s, severity=%s)", job.ID, target.GroupID, target.Route, target.OnCall, job.Severity)
+		// 同时决定是否暂缓到上班时间（只决定一次，重试不会改为暂缓）
+		if now := time.Now(); h.hours.Hold() && job.Severity != models.SeverityCritical && !h.hours.IsOpen(now) {
+			job.HeldUntil = h.hours.NextOpen(now)
+			log.Printf("[Escalation] Job %s held until %s (outside business hours)", job.ID, job.HeldUntil.Format(time.RFC3339))
+		}
+		h.saveJob(ctx, job)
+	}
+	groupID := job.Target.GroupID
+	held := job.Held(time.Now())
 
-	// 1. 邀请用户到技术支持群
-	if conv.SenderID != "" {
-		log.Printf("[Escalation] Inviting user %s to group %s", conv.SenderID, h.escalationGroupID)
-		if err := h.feishuClient.InviteUserToChat(ctx, h.escalationGroupID, conv.SenderID); err != nil {
-			log.Printf("[Escalation] Failed to invite user (may already be in group): %v", err)
-			// 邀请失败不阻塞流程（用户可能已在群中）
+	// 1. 邀请用户到技术支持群（暂缓的任务在上班后发出摘要时再邀请）
+	if !job.Invited && !held {
+		if conv.SenderID != "" {
+			log.Printf("[Escalation] Inviting user %s to group %s", conv.SenderID, groupID)
+			if err := h.feishuClient.InviteUserToChat(ctx, groupID, conv.SenderID); err != nil {
+				log.Printf("[Escalation] Failed to invite user (may already be in group): %v", err)
+				// 邀请失败不阻塞流程（用户可能已在群中），也不重试
+			}
 		}
+		job.Invited = true
 	}
 
-	// 2. 发送摘要到群组（创建话题根消息），并 @用户
-	summary := conv.GetInfoSummary()
-	log.Printf("[Escalation] Sending summary to group %s with @user %s", h.escalationGroupID, conv.SenderID)
+	// 2. 生成工单号
+	if job.TicketID == "" {
+		id, err := h.tickets.NextID(ctx)
+		if err != nil {
+			log.Printf("[Escalation] Failed to create ticket: %v", err)
+			return nil, h.retryJob(ctx, job, err)
+		}
+		job.TicketID = id
+		h.saveJob(ctx, job)
+	}
+	t := h.jobTicket(ctx, job)
 
-	// 根据模式选择标题
-	title := "用户问题反馈"
-	if conv.Mode == models.ModeSuggestion {
-		title = "用户建议反馈"
+	// 非工作时间暂缓：告知用户工单号和上班时间，到时由后台任务继续
+	if held {
+		return nil, h.holdJob(ctx, job, t)
 	}
 
-	rootMsgID, err := h.feishuClient.SendPostMessage(ctx, h.escalationGroupID, title, summary, conv.SenderID)
-	if err != nil {
-		log.Printf("[Escalation] Failed to send summary: %v", err)
-		return err
+	// 3. 自动分配负责人（只分配一次，失败或无人可分配时不分配；暂缓的任务在上班后分配）
+	if !job.Assigned && !job.SummaryPosted() {
+		assignee, err := h.assigner.Pick(ctx, job.Target.Route)
+		if err != nil {
+			log.Printf("[Escalation] Failed to auto-assign %s: %v", t.ID, err)
+		} else if assignee != "" {
+			log.Printf("[Escalation] Ticket %s auto-assigned to %s", t.ID, assignee)
+		}
+		job.Assignee = assignee
+		job.Assigned = true
+		h.saveJob(ctx, job)
+		t.Assignee = assignee
 	}
-	log.Printf("[Escalation] Summary sent, rootMsgID=%s", rootMsgID)
 
-	// 3. 在同一话题内回复文件（下载 → 重新上传 → 话题内回复）
-	if conv.HasFiles() && rootMsgID != "" {
-		for _, f := range conv.Files {
-			if err := h.forwardFileInThread(ctx, rootMsgID, f); err != nil {
-				log.Printf("[Escalation] File thread reply failed for %s: %v", f.FileName, err)
-				// 单个文件失败不影响整体流程，继续处理下一个文件
+	// 4. 发送摘要到群组（创建话题根消息），并 @用户、值班人员和路由配置的人，状态中 @负责人
+	if !job.SummaryPosted() {
+		log.Printf("[Escalation] Sending summary to group %s with @user %s", groupID, conv.SenderID)
+		rootMsgID, err := h.feishuClient.SendPostParagraphs(ctx, groupID, ticketTitle(t), ticketParagraphs(t), jobUUID(job, "summary"))
+		if err != nil {
+			log.Printf("[Escalation] Failed to send summary: %v", err)
+			return nil, h.retryJob(ctx, job, err)
+		}
+		log.Printf("[Escalation] Summary sent, ticket=%s, rootMsgID=%s", t.ID, rootMsgID)
+		job.RootMsgID = rootMsgID
+		h.saveJob(ctx, job)
+	}
+	t.RootMsgID = job.RootMsgID
+
+	// 紧急问题置顶摘要（失败不阻塞流程，也不重试）
+	if job.Severity == models.SeverityCritical && h.critical.Pin && !job.Pinned {
+		if err := h.feishuClient.PinMessage(ctx, job.RootMsgID); err != nil {
+			log.Printf("[Escalation] Failed to pin summary of %s: %v", t.ID, err)
+		}
+		job.Pinned = true
+		h.saveJob(ctx, job)
+	}
+
+	// 之后的步骤失败不影响其他步骤，记录第一个错误后统一重试
+	var pending error
+
+	// 5. 保存工单
+	if !job.TicketSaved {
+		if err := h.tickets.Save(ctx, t); err != nil {
+			log.Printf("[Escalation] Failed to save ticket %s: %v", t.ID, err)
+			pending = err
+		} else {
+			job.TicketSaved = true
+			h.saveJob(ctx, job)
+		}
+	}
+
+	// 6. 在同一话题内回复文件（下载 → 重新上传 → 话题内回复）
+	for _, f := range conv.Files {
+		if job.FileSent(f.FileKey) {
+			continue
+		}
+		if err := h.forwardFileInThread(ctx, job.RootMsgID, f, jobUUID(job, f.FileKey)); err != nil {
+			log.Printf("[Escalation] File thread reply failed for %s: %v", f.FileName, err)
+			if pending == nil {
+				pending = err
 			}
+			continue
 		}
+		job.FilesSent = append(job.FilesSent, f.FileKey)
+		h.saveJob(ctx, job)
 	}
 
-	// 4. 通知用户
-	userMsg := "✅ 您的问题已提交给技术支持团队，我们会尽快处理！\nYour issue has been submitted to the support team. We'll handle it ASAP!\n\n您已被邀请到技术支持群，可以在群里直接跟进问题。\nYou've been invited to the support group where you can follow up directly."
-	if err := h.feishuClient.SendTextMessage(ctx, conv.ChatID, userMsg); err != nil {
-		log.Printf("[Escalation] Failed to notify user: %v", err)
+	// 7. 通知用户（非工作时间附上上班时间和预计首次回复时间）
+	if !job.Notified {
+		// 非工作时间不承诺「尽快处理」，改为说明上班后处理
+		submitted := fmt.Sprintf("✅ 您的问题已提交给技术支持团队，我们会尽快处理！工单号：%s\nYour issue has been submitted to the support team. We'll handle it ASAP! Ticket: %s", t.ID, t.ID)
+		notice := h.offHoursNotice(time.Now())
+		if notice != "" {
+			submitted = fmt.Sprintf("✅ 您的问题已提交给技术支持团队，工单号：%s\nYour issue has been submitted to the support team. Ticket: %s", t.ID, t.ID)
+		}
+		userMsg := submitted + "\n\n您已被邀请到技术支持群，可以在群里直接跟进问题。\nYou've been invited to the support group where you can follow up directly."
+		if notice != "" {
+			userMsg += "\n\n" + notice
+		}
+		if err := h.feishuClient.SendTextMessage(ctx, conv.ChatID, userMsg); err != nil {
+			log.Printf("[Escalation] Failed to notify user: %v", err)
+			if pending == nil {
+				pending = err
+			}
+		} else {
+			job.Notified = true
+		}
 	}
 
-	log.Printf("[Escalation] Completed for chat %s", conv.ChatID)
-	return nil
+	if pending != nil {
+		return t, h.retryJob(ctx, job, pending)
+	}
+
+	job.Status = models.EscalationDone
+	job.NextAttemptAt = time.Time{}
+	job.LastError = ""
+	h.saveJob(ctx, job)
+	log.Printf("[Escalation] Completed for chat %s, ticket %s", conv.ChatID, t.ID)
+	return t, nil
+}
+
+// jobTicket 返回任务对应的工单：已保存时读取最新的工单（可能已被技术支持修改），否则根据会话快照创建。
+func (h *EscalationHandler) jobTicket(ctx context.Context, job *models.EscalationJob) *models.Ticket {
+	if job.TicketSaved {
+		t, err := h.tickets.Get(ctx, job.TicketID)
+		if err != nil {
+			log.Printf("[Escalation] Failed to load ticket %s: %v", job.TicketID, err)
+		}
+		if t != nil {
+			return t
+		}
+	}
+
+	t := models.NewTicket(job.Conversation)
+	t.ID = job.TicketID
+	t.GroupID = job.Target.GroupID
+	t.Route = job.Target.Route
+	t.Title = job.Target.Title
+	t.Mentions = job.Target.Mentions
+	t.OnCall = job.Target.OnCall
+	t.Severity = job.Severity
+	t.Priority = job.Priority
+	t.Assignee = job.Assignee
+	if !job.HeldUntil.IsZero() {
+		t.SLAStart = job.HeldUntil // 暂缓的工单从上班时间起计算 SLA
+	}
+	t.RootMsgID = job.RootMsgID
+	t.CreatedAt = job.CreatedAt
+	return t
+}
+
+// holdJob 暂缓任务到上班时间：保存状态为 queued 的工单（用户可查询），首次暂缓时告知用户，
+// 任务在 HeldUntil 由后台任务继续执行；返回 ErrEscalationHeld。摘要发出后步骤 5 保存为待处理。
+func (h *EscalationHandler) holdJob(ctx context.Context, job *models.EscalationJob, t *models.Ticket) error {
+	if !job.QueuedSaved {
+		t.Status = models.TicketQueued
+		if err := h.tickets.Save(ctx, t); err != nil {
+			log.Printf("[Escalation] Failed to save queued ticket %s: %v", t.ID, err)
+		} else {
+			job.QueuedSaved = true
+		}
+	}
+
+	if !job.HoldNotified {
+		zh, en := h.hours.Format(job.HeldUntil)
+		msg := fmt.Sprintf("🌙 现在是非工作时间，您的问题已受理（工单号：%s），将在 %s 上班后提交给技术支持团队。\nIt's outside business hours. Your issue has been received (ticket %s) and will be passed to the support team when we open at %s.",
+			t.ID, zh, t.ID, en)
+		if expected := h.hours.ExpectedResponse(job.HeldUntil); !expected.IsZero() {
+			zh, en := h.hours.Format(expected)
+			msg += fmt.Sprintf("\n预计首次回复时间：%s 前\nExpected first response by %s", zh, en)
+		}
+		if err := h.feishuClient.SendTextMessage(ctx, job.Conversation.ChatID, msg); err != nil {
+			log.Printf("[Escalation] Failed to notify user of held job %s: %v", job.ID, err)
+		} else {
+			job.HoldNotified = true
+		}
+	}
+
+	job.Status = models.EscalationPending
+	job.NextAttemptAt = job.HeldUntil
+	h.saveJob(ctx, job)
+	return ErrEscalationHeld
+}
+
+// offHoursNotice 返回非工作时间的提示（上班时间和预计首次回复时间），工作时间内返回空。
+func (h *EscalationHandler) offHoursNotice(now time.Time) string {
+	if h.hours.IsOpen(now) {
+		return ""
+	}
+	open := h.hours.NextOpen(now)
+	if open.IsZero() {
+		return ""
+	}
+	zh, en := h.hours.Format(open)
+	msg := fmt.Sprintf("🌙 现在是非工作时间，技术支持将在 %s 上班后处理。\nIt's outside business hours. The support team will pick this up when we open at %s.", zh, en)
+	if expected := h.hours.ExpectedResponse(now); !expected.IsZero() {
+		zh, en := h.hours.Format(expected)
+		msg += fmt.Sprintf("\n预计首次回复时间：%s 前\nExpected first response by %s", zh, en)
+	}
+	return msg
+}
+
+// retryJob 记录失败并按指数退避安排重试，重试次数用尽时标记任务失败；返回原错误。
+func (h *EscalationHandler) retryJob(ctx context.Context, job *models.EscalationJob, err error) error {
+	job.Attempts++
+	job.LastError = err.Error()
+	if job.Attempts >= escalationMaxAttempts {
+		job.Status = models.EscalationFailed
+		job.NextAttemptAt = time.Time{}
+		log.Printf("[Escalation] Job %s failed after %d attempts: %v", job.ID, job.Attempts, err)
+	} else {
+		delay := escalationRetryBase << (job.Attempts - 1)
+		if delay > escalationRetryMax || delay <= 0 {
+			delay = escalationRetryMax
+		}
+		job.NextAttemptAt = time.Now().Add(delay)
+		log.Printf("[Escalation] Job %s will retry in %s (attempt %d): %v", job.ID, delay, job.Attempts, err)
+	}
+	h.saveJob(ctx, job)
+	return err
+}
+
+// saveJob 保存任务进度（失败只记录日志：已完成的外部操作以 uuid 去重，不会因进度丢失而重复）。
+func (h *EscalationHandler) saveJob(ctx context.Context, job *models.EscalationJob) {
+	if err := h.tickets.SaveJob(ctx, job); err != nil {
+		log.Printf("[Escalation] Failed to save job %s: %v", job.ID, err)
+	}
+}
+
+// jobUUID 返回任务中某个发送操作的请求去重 uuid（飞书限制 50 个字符以内）。
+func jobUUID(job *models.EscalationJob, step string) string {
+	sum := sha1.Sum([]byte(job.ID + "/" + step))
+	return hex.EncodeToString(sum[:])
 }
 
 // forwardFileInThread 将用户上传的文件下载后重新上传，然后在话题内回复。
-func (h *EscalationHandler) forwardFileInThread(ctx context.Context, rootMsgID string, f models.FileInfo) error {
+// uuid 可选，用于话题内回复的请求去重。
+func (h *EscalationHandler) forwardFileInThread(ctx context.Context, rootMsgID string, f models.FileInfo, uuid string) error {
 	log.Printf("[Escalation] Forwarding file %s in thread (parentMsg=%s)", f.FileName, rootMsgID)
 
 	// Step 1: 从原始消息下载文件
@@ -103,7 +367,7 @@ func (h *EscalationHandler) forwardFileInThread(ctx context.Context, rootMsgID s
 	log.Printf("[Escalation] Re-uploaded file, new fileKey=%s", newFileKey)
 
 	// Step 3: 在话题内回复文件
-	if err := h.feishuClient.ReplyFileInThread(ctx, rootMsgID, newFileKey); err != nil {
+	if err := h.feishuClient.ReplyFileInThread(ctx, rootMsgID, newFileKey, uuid); err != nil {
 		return err
 	}
 
@@ -111,7 +375,7 @@ func (h *EscalationHandler) forwardFileInThread(ctx context.Context, rootMsgID s
 	return nil
 }
 
-// GetEscalationGroupID 返回转人工群组 ID。
+// GetEscalationGroupID 返回默认转人工群组 ID。
 func (h *EscalationHandler) GetEscalationGroupID() string {
 	return h.escalationGroupID
 }
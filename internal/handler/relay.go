@@ -0,0 +1,49 @@
+// Package handler 提供工单话题与用户私聊之间的双向转发。
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// RelayToUser 将技术支持在工单话题内的回复转发到用户私聊：文本直接发送，其他类型消息整条转发。
+func (h *EscalationHandler) RelayToUser(ctx context.Context, t *models.Ticket, msg feishu.ThreadMessage) error {
+	header := fmt.Sprintf("💬 技术支持回复 / Support reply · %s", t.ID)
+	if msg.MsgType == "text" {
+		text := fmt.Sprintf("%s\n\n%s\n\n直接在此回复即可继续沟通。/ Reply here to continue the conversation.", header, strings.TrimSpace(msg.Content))
+		return h.feishuClient.SendTextMessage(ctx, t.ChatID, text)
+	}
+
+	if err := h.feishuClient.SendTextMessage(ctx, t.ChatID, header); err != nil {
+		return err
+	}
+	return h.feishuClient.ForwardMessage(ctx, msg.MessageID, t.ChatID)
+}
+
+// RelayToThread 将用户在私聊中的后续消息回复到工单话题：文本加上用户前缀，文件重新上传后在话题内回复。
+func (h *EscalationHandler) RelayToThread(ctx context.Context, t *models.Ticket, messageID, content, fileKey string) error {
+	if t.RootMsgID == "" {
+		return fmt.Errorf("ticket %s has no thread", t.ID)
+	}
+	log.Printf("[Relay] User message %s → ticket %s thread", messageID, t.ID)
+
+	if fileKey == "" {
+		text := fmt.Sprintf("💬 用户回复 / User reply:\n%s", content)
+		return h.feishuClient.ReplyTextInThread(ctx, t.RootMsgID, text)
+	}
+
+	fileName := "attachment"
+	if strings.HasPrefix(content, "上传了文件: ") {
+		fileName = strings.TrimPrefix(content, "上传了文件: ")
+	}
+	return h.forwardFileInThread(ctx, t.RootMsgID, models.FileInfo{
+		MessageID: messageID,
+		FileKey:   fileKey,
+		FileName:  fileName,
+	}, "")
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// Route 按路由规则为会话选择转人工目标：第一条命中的规则，都不命中时为默认群组；
+// 同时记录该路由当前的值班人员。
+func (h *EscalationHandler) Route(conv *models.Conversation) models.RouteTarget {
+	target := models.RouteTarget{GroupID: h.escalationGroupID}
+	for _, r := range h.routes {
+		if r.Matches(conv) {
+			target = r.Target()
+			break
+		}
+	}
+	if h.oncall != nil {
+		target.OnCall = h.oncall.Current(target.Route, time.Now()).User
+	}
+	return target
+}
+
+// GroupRoutes 返回发往该群组的路由名称（默认群组为 ""），用于群内 oncall 命令。
+func (h *EscalationHandler) GroupRoutes(chatID string) []string {
+	var names []string
+	if chatID == h.escalationGroupID {
+		names = append(names, "")
+	}
+	for _, r := range h.routes {
+		if r.GroupID == chatID {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// RouteMatches 返回会话命中的全部路由名称（按配置顺序，第一条生效），用于路由预览。
+func (h *EscalationHandler) RouteMatches(conv *models.Conversation) []string {
+	var names []string
+	for _, r := range h.routes {
+		if r.Matches(conv) {
+			names = append(names, r.Name)
+		}
+	}
+	return names
+}
+
+// IsEscalationGroup 判断群组是否为转人工群组（默认群组或任一路由的目标群组）。
+func (h *EscalationHandler) IsEscalationGroup(chatID string) bool {
+	if chatID == h.escalationGroupID {
+		return true
+	}
+	for _, r := range h.routes {
+		if r.GroupID == chatID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,128 @@
+// Package handler 提供工单话题根消息的状态展示、用户通知与补充信息追加。
+package handler
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/even/feishu-bot/internal/feishu"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// ticketTitle 返回工单话题根消息的标题（含工单号，已分级时以严重程度开头，如 "[紧急] 用户问题反馈 T000123"）。
+func ticketTitle(t *models.Ticket) string {
+	title := fmt.Sprintf("%s %s", TitleFor(t.Title, t.Mode), t.ID)
+	if t.Severity != "" {
+		title = fmt.Sprintf("[%s] %s", t.Severity.Name(), title)
+	}
+	return title
+}
+
+// TitleFor 返回话题标题（不含工单号）：路由配置的标题，未配置时按模式。
+func TitleFor(title string, mode models.ConversationMode) string {
+	switch {
+	case title != "":
+		return title
+	case mode == models.ModeSuggestion:
+		return "用户建议反馈"
+	default:
+		return "用户问题反馈"
+	}
+}
+
+// ticketMentions 返回工单根消息中 @ 的人：提交用户、值班人员和路由配置的人（去重）。
+func ticketMentions(t *models.Ticket) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, id := range append([]string{t.UserID, t.OnCall}, t.Mentions...) {
+		if id != "" && !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// UpdateTicketMessage 编辑工单话题根消息：@用户（及值班人员、路由配置的人）+ 提交摘要 + 当前状态 / 负责人 / 优先级 / 标签。
+func (h *EscalationHandler) UpdateTicketMessage(ctx context.Context, t *models.Ticket) error {
+	if t.RootMsgID == "" {
+		return fmt.Errorf("ticket %s has no thread", t.ID)
+	}
+
+	if err := h.feishuClient.UpdatePostMessage(ctx, t.RootMsgID, ticketTitle(t), ticketParagraphs(t)); err != nil {
+		log.Printf("[Ticket] Failed to update root message of %s: %v", t.ID, err)
+		return err
+	}
+	return nil
+}
+
+// ticketParagraphs 构建工单话题根消息的内容：@用户等 + 提交摘要 + 处理状态。
+func ticketParagraphs(t *models.Ticket) [][]feishu.PostElement {
+	var paragraphs [][]feishu.PostElement
+	if mentions := ticketMentions(t); len(mentions) > 0 {
+		paragraphs = append(paragraphs, feishu.PostAts(mentions))
+	}
+	paragraphs = append(paragraphs, []feishu.PostElement{feishu.PostText(t.Draft().GetInfoSummary())})
+	return append(paragraphs, ticketStatusParagraphs(t)...)
+}
+
+// ticketStatusParagraphs 构建根消息中的处理状态段落。
+func ticketStatusParagraphs(t *models.Ticket) [][]feishu.PostElement {
+	paragraphs := [][]feishu.PostElement{
+		{feishu.PostText("【状态】" + t.StatusLabel())},
+	}
+	if t.Severity != "" {
+		paragraphs = append(paragraphs, []feishu.PostElement{feishu.PostText("【严重程度】" + t.Severity.Label())})
+	}
+	if t.Assignee != "" {
+		paragraphs = append(paragraphs, []feishu.PostElement{feishu.PostText("【负责人】"), feishu.PostAt(t.Assignee)})
+	}
+	if t.Priority != "" {
+		paragraphs = append(paragraphs, []feishu.PostElement{feishu.PostText("【优先级】" + t.Priority)})
+	}
+	if len(t.Tags) > 0 {
+		paragraphs = append(paragraphs, []feishu.PostElement{feishu.PostText("【标签】" + strings.Join(t.Tags, "、"))})
+	}
+	if t.MergedInto != "" {
+		paragraphs = append(paragraphs, []feishu.PostElement{feishu.PostText("【主工单】" + t.MergedInto)})
+	}
+	if len(t.Duplicates) > 0 {
+		paragraphs = append(paragraphs, []feishu.PostElement{feishu.PostText("【重复工单】" + strings.Join(t.Duplicates, "、"))})
+	}
+	return paragraphs
+}
+
+// NotifyStatus 在用户私聊中通知工单状态变更。
+func (h *EscalationHandler) NotifyStatus(ctx context.Context, t *models.Ticket) error {
+	msg := fmt.Sprintf("📌 工单 %s 状态更新 / Ticket %s status update: %s", t.ID, t.ID, t.StatusLabel())
+	if err := h.feishuClient.SendTextMessage(ctx, t.ChatID, msg); err != nil {
+		log.Printf("[Ticket] Failed to notify user of %s: %v", t.ID, err)
+		return err
+	}
+	return nil
+}
+
+// AppendSupplement 将用户补充的信息追加到工单话题（@发起请求的技术支持），附件重新上传后在话题内回复。
+func (h *EscalationHandler) AppendSupplement(ctx context.Context, t *models.Ticket, conv *models.Conversation) error {
+	if t.RootMsgID == "" {
+		return fmt.Errorf("ticket %s has no thread", t.ID)
+	}
+
+	text := "📎 用户补充信息 / Supplement from user\n" + conv.GetSupplementSummary()
+	if conv.Supplement != nil && conv.Supplement.RequestedBy != "" {
+		text = fmt.Sprintf(`<at user_id="%s"></at> %s`, conv.Supplement.RequestedBy, text)
+	}
+	if err := h.feishuClient.ReplyTextInThread(ctx, t.RootMsgID, text); err != nil {
+		log.Printf("[Ticket] Failed to append supplement to %s: %v", t.ID, err)
+		return err
+	}
+
+	for _, f := range conv.Files {
+		if err := h.forwardFileInThread(ctx, t.RootMsgID, f, ""); err != nil {
+			log.Printf("[Ticket] Supplement file %s for %s failed: %v", f.FileName, t.ID, err)
+		}
+	}
+	return nil
+}
@@ -1,4 +1,4 @@
-// Package llm 提供 LLM 客户端（OpenAI 兼容），用于从用户消息中提取信息。
+// Package llm 提供 LLM 客户端（OpenAI 兼容），用于从用户消息中提取信息和判断问题的严重程度。
 package llm
 
 import (
@@ -8,6 +8,7 @@ import (
 	"log"
 	"strings"
 
+	"github.com/even/feishu-bot/pkg/models"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -15,43 +16,26 @@ import (
 type Client interface {
 	// ExtractInfo 从用户的单条消息中提取信息字段。
 	ExtractInfo(ctx context.Context, userMessage string, collectedInfo map[string]string) (*ExtractionResult, error)
+	// ClassifySeverity 根据提交的问题摘要判断严重程度。
+	ClassifySeverity(ctx context.Context, issue string) (*SeverityResult, error)
 }
 
-// ExtractionResult 表示从单条消息中提取的信息。
-type ExtractionResult struct {
-	Issue          string `json:"issue"`           // 问题描述
-	OccurTime      string `json:"occur_time"`      // 发生时间
-	Reproducible   string `json:"reproducible"`    // 是否必现
-	VPN            string `json:"vpn"`             // 是否使用VPN
-	AppVersion     string `json:"app_version"`     // 应用版本
-	GlassesVersion string `json:"glasses_version"` // 眼镜版本
-	GlassesSN      string `json:"glasses_sn"`      // 眼镜SN号
-	RingVersion    string `json:"ring_version"`    // 戒指版本
-	RingSN         string `json:"ring_sn"`         // 戒指SN号
-	PhoneModel     string `json:"phone_model"`     // 手机型号
-	PhoneOS        string `json:"phone_os"`        // 手机系统版本
+// SeverityResult 表示 LLM 判断的严重程度。
+type SeverityResult struct {
+	Severity models.Severity
+	Reason   string // 判断理由（记录日志）
 }
 
-// AllFieldKeys 返回所有字段的 key 列表（与 JSON tag 一致）。
-var AllFieldKeys = []string{
-	"issue", "occur_time", "reproducible", "vpn",
-	"app_version", "glasses_version", "glasses_sn",
-	"ring_version", "ring_sn", "phone_model", "phone_os",
+// ExtractionResult 表示从单条消息中提取的信息。
+type ExtractionResult struct {
+	Fields map[string]ExtractedValue // 字段 key → 提取值（key 与字段配置一致，未提取到的字段不存在）
 }
 
-// FieldDisplayNames 字段 key → 中英双语显示名称。
-var FieldDisplayNames = map[string]string{
-	"issue":           "问题描述 / Issue Description",
-	"occur_time":      "发生时间 / Time of Occurrence",
-	"reproducible":    "是否必现 / Reproducible?",
-	"vpn":             "是否使用VPN / Using VPN?",
-	"app_version":     "应用版本 / App Version",
-	"glasses_version": "眼镜版本 / Glasses Firmware",
-	"glasses_sn":      "眼镜SN号 / Glasses SN",
-	"ring_version":    "戒指版本 / Ring Firmware",
-	"ring_sn":         "戒指SN号 / Ring SN",
-	"phone_model":     "手机型号 / Phone Model",
-	"phone_os":        "手机系统版本 / Phone OS Version",
+// ExtractedValue 表示一个字段的提取值及其可信程度。
+type ExtractedValue struct {
+	Value      string  // 提取值
+	Confidence float64 // 置信度 0~1，LLM 未返回时视为 1
+	Source     string  // 值在用户消息中对应的原文片段
 }
 
 // ProviderConfig LLM 提供商配置。
@@ -60,21 +44,24 @@ type ProviderConfig struct {
 	APIKey   string
 	BaseURL  string
 	Model    string
+	Fields   []models.FieldDef
 }
 
 // NewClient 创建 LLM 客户端的便捷函数。
 func NewClient(cfg *ProviderConfig) (Client, error) {
-	return NewOpenAICompatibleClient(cfg.BaseURL, cfg.APIKey, cfg.Model)
+	return NewOpenAICompatibleClient(cfg.BaseURL, cfg.APIKey, cfg.Model, cfg.Fields)
 }
 
 // OpenAICompatibleClient OpenAI 兼容客户端实现。
 type OpenAICompatibleClient struct {
-	client *openai.Client
-	model  string
+	client       *openai.Client
+	model        string
+	fields       []models.FieldDef
+	systemPrompt string
 }
 
-// NewOpenAICompatibleClient 创建新的 OpenAI 兼容客户端。
-func NewOpenAICompatibleClient(baseURL, apiKey, model string) (*OpenAICompatibleClient, error) {
+// NewOpenAICompatibleClient 创建新的 OpenAI 兼容客户端，System Prompt 由字段配置生成。
+func NewOpenAICompatibleClient(baseURL, apiKey, model string, fields []models.FieldDef) (*OpenAICompatibleClient, error) {
 	if apiKey == "" {
 		return nil, fmt.Errorf("API key is required")
 	}
@@ -89,26 +76,36 @@ func NewOpenAICompatibleClient(baseURL, apiKey, model string) (*OpenAICompatible
 	config.BaseURL = baseURL
 
 	return &OpenAICompatibleClient{
-		client: openai.NewClientWithConfig(config),
-		model:  model,
+		client:       openai.NewClientWithConfig(config),
+		model:        model,
+		fields:       fields,
+		systemPrompt: buildSystemPrompt(fields),
 	}, nil
 }
 
-const systemPrompt = `You are a tech support information collector. Your ONLY task is to extract the following 11 fields from the user's CURRENT message. The user may write in Chinese or English — handle both languages.
-
-Fields to collect:
-1. issue - Problem description (what happened, what the user was doing before the issue)
-2. occur_time - Time of occurrence (e.g. "today 3pm", "2026-02-09 14:00", "今天下午3点")
-3. reproducible - Is it reproducible? (e.g. "yes", "no", "sometimes", "是", "否", "偶现")
-4. vpn - Using VPN? If yes, specify region/node (e.g. "no", "yes, US node", "否", "是，香港节点")
-5. app_version - App version number (e.g. 2.0.6, v1.2.3)
-6. glasses_version - Glasses firmware version (e.g. 1.2.0, v2.1)
-7. glasses_sn - Glasses serial number (e.g. G2xxxxxxx, SN12345)
-8. ring_version - Ring firmware version (e.g. 1.0, v2.1)
-9. ring_sn - Ring serial number (e.g. R1xxxxxxx, SN67890)
-10. phone_model - Phone model (e.g. iPhone 15 Pro, Xiaomi 14, Samsung Galaxy S24)
-11. phone_os - Phone OS version (e.g. Android 15, iOS 18.3.2)
+// buildSystemPrompt 根据字段配置生成信息提取的 System Prompt。
+func buildSystemPrompt(fields []models.FieldDef) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("You are a tech support information collector. Your ONLY task is to extract the following %d fields from the user's CURRENT message. The user may write in Chinese or English — handle both languages.\n\n", len(fields)))
 
+	sb.WriteString("Fields to collect:\n")
+	for i, f := range fields {
+		desc := f.Description
+		if desc == "" {
+			desc = f.Name
+		}
+		sb.WriteString(fmt.Sprintf("%d. %s - %s", i+1, f.Key, desc))
+		if len(f.Examples) > 0 {
+			quoted := make([]string, 0, len(f.Examples))
+			for _, ex := range f.Examples {
+				quoted = append(quoted, fmt.Sprintf("%q", ex))
+			}
+			sb.WriteString(fmt.Sprintf(" (e.g. %s)", strings.Join(quoted, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+
+	sb.WriteString(`
 Strict rules:
 - Extract ONLY from the user's current message
 - If a field is not mentioned, return empty string ""
@@ -116,14 +113,26 @@ Strict rules:
 - Do NOT treat greetings ("hi", "hello", "你好") as any info
 - Do NOT guess or fabricate info
 - If the user corrects previous info (e.g. "wrong version, it's v3.0"), return the new value
-- Distinguish between app version vs glasses/ring firmware version
-- Distinguish between glasses SN vs ring SN
-- Distinguish between phone model (hardware) vs phone OS (software)
+- Pay attention to what each field is NOT (e.g. app version vs firmware version, glasses SN vs ring SN)
 - Keep extracted values concise and accurate
 - Preserve the user's original language in the extracted values
 
+For every field return an object with:
+- value: the extracted value ("" if not mentioned)
+- confidence: 0.0-1.0. Use 0.9+ only when the user stated the value explicitly for this field (e.g. "glasses firmware 1.2.0"). Use 0.5 or lower when you had to infer which field the value belongs to (e.g. a bare "1.2.0" that could be the glasses or the ring firmware)
+- source: the exact span of the user's message the value was taken from ("" if not mentioned)
+
 Return strict JSON only, no other text:
-{"issue": "", "occur_time": "", "reproducible": "", "vpn": "", "app_version": "", "glasses_version": "", "glasses_sn": "", "ring_version": "", "ring_sn": "", "phone_model": "", "phone_os": ""}`
+`)
+
+	template := make([]string, 0, len(fields))
+	for _, f := range fields {
+		template = append(template, fmt.Sprintf("%q: {\"value\": \"\", \"confidence\": 0.0, \"source\": \"\"}", f.Key))
+	}
+	sb.WriteString("{" + strings.Join(template, ", ") + "}")
+
+	return sb.String()
+}
 
 // ExtractInfo 从用户的单条消息中提取信息。
 func (c *OpenAICompatibleClient) ExtractInfo(ctx context.Context, userMessage string, collectedInfo map[string]string) (*ExtractionResult, error) {
@@ -131,18 +140,17 @@ func (c *OpenAICompatibleClient) ExtractInfo(ctx context.Context, userMessage st
 
 	messages = append(messages, openai.ChatCompletionMessage{
 		Role:    openai.ChatMessageRoleSystem,
-		Content: systemPrompt,
+		Content: c.systemPrompt,
 	})
 
 	// 构建上下文：已收集的信息 + 当前消息
 	var userPrompt strings.Builder
 	userPrompt.WriteString("Already collected info (reference only, do NOT copy into result):\n")
-	for _, key := range AllFieldKeys {
-		name := FieldDisplayNames[key]
-		if val, ok := collectedInfo[key]; ok && val != "" {
-			userPrompt.WriteString(fmt.Sprintf("- %s: %s (collected)\n", name, val))
+	for _, f := range c.fields {
+		if val, ok := collectedInfo[f.Key]; ok && val != "" {
+			userPrompt.WriteString(fmt.Sprintf("- %s: %s (collected)\n", f.Name, val))
 		} else {
-			userPrompt.WriteString(fmt.Sprintf("- %s: not yet collected\n", name))
+			userPrompt.WriteString(fmt.Sprintf("- %s: not yet collected\n", f.Name))
 		}
 	}
 	userPrompt.WriteString(fmt.Sprintf("\nUser's current message: %s\n\nExtract info from this message and return JSON.", userMessage))
@@ -173,11 +181,11 @@ func (c *OpenAICompatibleClient) ExtractInfo(ctx context.Context, userMessage st
 	content := resp.Choices[0].Message.Content
 	log.Printf("[LLM] Raw response: %s", content)
 
-	return parseExtractionResult(content)
+	return parseExtractionResult(content, c.fields)
 }
 
-// parseExtractionResult 解析 LLM 返回的提取结果。
-func parseExtractionResult(content string) (*ExtractionResult, error) {
+// parseExtractionResult 解析 LLM 返回的提取结果，只保留字段配置中定义的 key。
+func parseExtractionResult(content string, fields []models.FieldDef) (*ExtractionResult, error) {
 	content = strings.TrimSpace(content)
 
 	// 去除 markdown 代码块标记
@@ -197,31 +205,54 @@ func parseExtractionResult(content string) (*ExtractionResult, error) {
 
 	content = strings.TrimSpace(content)
 
-	var result ExtractionResult
-	if err := json.Unmarshal([]byte(content), &result); err != nil {
+	result := &ExtractionResult{Fields: make(map[string]ExtractedValue)}
+
+	var raw map[string]interface{}
+	if err := json.Unmarshal([]byte(content), &raw); err != nil {
 		log.Printf("[LLM] Failed to parse response as JSON: %v (content: %s)", err, content)
-		return &ExtractionResult{}, nil // 解析失败返回空结果
+		return result, nil // 解析失败返回空结果
 	}
 
 	// 清理提取的值（去除空白和无意义内容）
-	result.Issue = cleanExtractedValue(result.Issue)
-	result.OccurTime = cleanExtractedValue(result.OccurTime)
-	result.Reproducible = cleanExtractedValue(result.Reproducible)
-	result.VPN = cleanExtractedValue(result.VPN)
-	result.AppVersion = cleanExtractedValue(result.AppVersion)
-	result.GlassesVersion = cleanExtractedValue(result.GlassesVersion)
-	result.GlassesSN = cleanExtractedValue(result.GlassesSN)
-	result.RingVersion = cleanExtractedValue(result.RingVersion)
-	result.RingSN = cleanExtractedValue(result.RingSN)
-	result.PhoneModel = cleanExtractedValue(result.PhoneModel)
-	result.PhoneOS = cleanExtractedValue(result.PhoneOS)
-
-	log.Printf("[LLM] Extracted: issue=%q, occur_time=%q, reproducible=%q, vpn=%q, app_version=%q, glasses_version=%q, glasses_sn=%q, ring_version=%q, ring_sn=%q, phone_model=%q, phone_os=%q",
-		result.Issue, result.OccurTime, result.Reproducible, result.VPN,
-		result.AppVersion, result.GlassesVersion, result.GlassesSN,
-		result.RingVersion, result.RingSN, result.PhoneModel, result.PhoneOS)
-
-	return &result, nil
+	for _, f := range fields {
+		ev, ok := parseExtractedValue(raw[f.Key])
+		if !ok {
+			continue
+		}
+		if ev.Value = cleanExtractedValue(ev.Value); ev.Value != "" {
+			result.Fields[f.Key] = ev
+		}
+	}
+
+	log.Printf("[LLM] Extracted: %v", result.Fields)
+
+	return result, nil
+}
+
+// parseExtractedValue 解析单个字段：支持 {"value", "confidence", "source"} 对象，也兼容直接返回字符串。
+func parseExtractedValue(val interface{}) (ExtractedValue, bool) {
+	switch v := val.(type) {
+	case nil:
+		return ExtractedValue{}, false
+	case string:
+		return ExtractedValue{Value: v, Confidence: 1}, true
+	case map[string]interface{}:
+		ev := ExtractedValue{Confidence: 1}
+		if s, ok := v["value"].(string); ok {
+			ev.Value = s
+		} else if v["value"] != nil {
+			ev.Value = fmt.Sprint(v["value"])
+		}
+		if c, ok := v["confidence"].(float64); ok && c >= 0 && c <= 1 {
+			ev.Confidence = c
+		}
+		if s, ok := v["source"].(string); ok {
+			ev.Source = strings.TrimSpace(s)
+		}
+		return ev, true
+	default:
+		return ExtractedValue{Value: fmt.Sprint(v), Confidence: 1}, true
+	}
 }
 
 // cleanExtractedValue 清理提取的值。
@@ -242,19 +273,69 @@ func cleanExtractedValue(val string) string {
 	return val
 }
 
-// ToFieldMap 将 ExtractionResult 转为 map[string]string，方便与 RequiredFields 统一处理。
+// ToFieldMap 返回字段 key → 提取值的映射，方便与字段配置统一处理。
 func (r *ExtractionResult) ToFieldMap() map[string]string {
-	return map[string]string{
-		"issue":           r.Issue,
-		"occur_time":      r.OccurTime,
-		"reproducible":    r.Reproducible,
-		"vpn":             r.VPN,
-		"app_version":     r.AppVersion,
-		"glasses_version": r.GlassesVersion,
-		"glasses_sn":      r.GlassesSN,
-		"ring_version":    r.RingVersion,
-		"ring_sn":         r.RingSN,
-		"phone_model":     r.PhoneModel,
-		"phone_os":        r.PhoneOS,
+	m := make(map[string]string, len(r.Fields))
+	for k, v := range r.Fields {
+		m[k] = v.Value
+	}
+	return m
+}
+
+// severitySystemPrompt 是严重程度分级的 System Prompt。
+const severitySystemPrompt = `You triage support tickets for smart glasses. Classify the severity of the reported issue.
+
+Levels:
+- critical: safety risk (overheating, burning smell, smoke, swelling battery, skin burn, electric shock) or an outage affecting many users
+- high: a core function is unusable with no workaround (cannot power on, cannot connect at all, display black, repeated crashes)
+- medium: a function misbehaves but there is a workaround (lost pairing, occasional disconnects, sync delays)
+- low: cosmetic issues, usability feedback, questions
+
+Return ONLY a JSON object: {"severity": "critical|high|medium|low", "reason": "one short sentence"}`
+
+// ClassifySeverity 根据提交的问题摘要判断严重程度。
+func (c *OpenAICompatibleClient) ClassifySeverity(ctx context.Context, issue string) (*SeverityResult, error) {
+	resp, err := c.client.CreateChatCompletion(
+		ctx,
+		openai.ChatCompletionRequest{
+			Model: c.model,
+			Messages: []openai.ChatCompletionMessage{
+				{Role: openai.ChatMessageRoleSystem, Content: severitySystemPrompt},
+				{Role: openai.ChatMessageRoleUser, Content: "Issue report:\n" + issue},
+			},
+			Temperature: 0,
+		},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("LLM 调用失败: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("LLM 没有返回结果")
+	}
+
+	content := resp.Choices[0].Message.Content
+	log.Printf("[LLM] Severity raw response: %s", content)
+	return parseSeverityResult(content)
+}
+
+// parseSeverityResult 解析 LLM 返回的严重程度，级别无法识别时返回错误（由调用方按关键词规则分级）。
+func parseSeverityResult(content string) (*SeverityResult, error) {
+	start := strings.Index(content, "{")
+	end := strings.LastIndex(content, "}")
+	if start < 0 || end <= start {
+		return nil, fmt.Errorf("LLM 返回的不是 JSON: %s", content)
+	}
+
+	var raw struct {
+		Severity string `json:"severity"`
+		Reason   string `json:"reason"`
+	}
+	if err := json.Unmarshal([]byte(content[start:end+1]), &raw); err != nil {
+		return nil, fmt.Errorf("解析严重程度失败: %w", err)
+	}
+	severity, ok := models.ParseSeverity(raw.Severity)
+	if !ok {
+		return nil, fmt.Errorf("未知的严重程度 %q", raw.Severity)
 	}
+	return &SeverityResult{Severity: severity, Reason: strings.TrimSpace(raw.Reason)}, nil
 }
@@ -0,0 +1,237 @@
+// Package oncall 提供值班排班：按路由每周轮值，临时替班优先，可从本地文件加载。
+package oncall
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/even/feishu-bot/internal/config"
+	"github.com/spf13/viper"
+)
+
+// DefaultRotation 是默认群组（没有命中路由）使用的轮值名称。
+const DefaultRotation = config.DefaultRouteName
+
+// 排班中的日期 / 时间格式（交接时区）。
+const (
+	dateLayout     = "2006-01-02"
+	clockLayout    = "15:04"
+	dateTimeLayout = "2006-01-02 15:04"
+)
+
+// Shift 是某个路由当前的值班安排。
+type Shift struct {
+	User     string    // 值班人员 open_id，空表示未配置
+	Until    time.Time // 下次交接（替班为替班结束）时间
+	Override bool      // 是否为临时替班
+}
+
+// Schedule 是值班排班。配置了排班文件时，文件修改后下次查询自动重新读取
+// （读取失败时保留之前的排班）。
+type Schedule struct {
+	mu       sync.Mutex
+	file     string
+	fallback *time.Location
+	modTime  time.Time
+	plan     *plan
+}
+
+// plan 是解析后的排班。
+type plan struct {
+	loc       *time.Location
+	anchor    time.Time           // 第一次交接时刻
+	rotations map[string][]string // 小写路由名称 → 轮值列表
+	overrides []override
+}
+
+// override 是解析后的临时替班。
+type override struct {
+	route      string // 小写路由名称，空表示所有路由
+	user       string
+	start, end time.Time
+}
+
+// New 根据配置创建排班；fallback 是未配置 timezone 时使用的时区。
+func New(cfg config.OnCallConfig, fallback *time.Location) (*Schedule, error) {
+	s := &Schedule{file: strings.TrimSpace(cfg.File), fallback: fallback}
+	if s.file == "" {
+		p, err := parsePlan(cfg, fallback)
+		if err != nil {
+			return nil, err
+		}
+		s.plan = p
+		return s, nil
+	}
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Enabled 判断是否配置了任何轮值或替班。
+func (s *Schedule) Enabled() bool {
+	p := s.current()
+	return len(p.rotations) > 0 || len(p.overrides) > 0
+}
+
+// Location 返回交接时区（用于显示交接时间）。
+func (s *Schedule) Location() *time.Location {
+	return s.current().loc
+}
+
+// Current 返回路由在 now 时刻的值班安排（路由为空或没有单独轮值时使用 default 轮值）。
+func (s *Schedule) Current(route string, now time.Time) Shift {
+	p := s.current()
+	route = strings.ToLower(route)
+
+	for _, o := range p.overrides {
+		if (o.route == "" || o.route == route) && !now.Before(o.start) && now.Before(o.end) {
+			return Shift{User: o.user, Until: o.end, Override: true}
+		}
+	}
+
+	users := p.rotations[route]
+	if len(users) == 0 {
+		users = p.rotations[DefaultRotation]
+	}
+	if len(users) == 0 {
+		return Shift{}
+	}
+	week, until := p.week(now)
+	idx := week % len(users)
+	if idx < 0 {
+		idx += len(users)
+	}
+	return Shift{User: users[idx], Until: until}
+}
+
+// week 返回 now 所在的轮值周序号（第一次交接起为 0）和该周结束的交接时刻。
+// 按日历加 7 天计算，交接时间不受夏令时影响。
+func (p *plan) week(now time.Time) (int, time.Time) {
+	n := int(now.Sub(p.anchor) / (7 * 24 * time.Hour))
+	for p.anchor.AddDate(0, 0, 7*n).After(now) {
+		n--
+	}
+	for !p.anchor.AddDate(0, 0, 7*(n+1)).After(now) {
+		n++
+	}
+	return n, p.anchor.AddDate(0, 0, 7*(n+1))
+}
+
+// current 返回当前排班，排班文件有修改时先重新读取。
+func (s *Schedule) current() *plan {
+	if s.file != "" {
+		if err := s.reload(); err != nil {
+			log.Printf("[OnCall] Failed to reload %s, keeping the previous schedule: %v", s.file, err)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.plan
+}
+
+// reload 在排班文件修改时间变化时重新读取并解析文件。
+func (s *Schedule) reload() error {
+	info, err := os.Stat(s.file)
+	if err != nil {
+		return fmt.Errorf("failed to stat oncall file: %w", err)
+	}
+	s.mu.Lock()
+	unchanged := s.plan != nil && info.ModTime().Equal(s.modTime)
+	s.mu.Unlock()
+	if unchanged {
+		return nil
+	}
+
+	v := viper.New()
+	v.SetConfigFile(s.file)
+	if err := v.ReadInConfig(); err != nil {
+		return fmt.Errorf("failed to read oncall file: %w", err)
+	}
+	var cfg config.OnCallConfig
+	if err := v.Unmarshal(&cfg); err != nil {
+		return fmt.Errorf("failed to unmarshal oncall file: %w", err)
+	}
+	p, err := parsePlan(cfg, s.fallback)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.plan, s.modTime = p, info.ModTime()
+	s.mu.Unlock()
+	log.Printf("[OnCall] Loaded schedule from %s (%d rotations, %d overrides)", s.file, len(p.rotations), len(p.overrides))
+	return nil
+}
+
+// parsePlan 校验并解析排班配置。
+func parsePlan(cfg config.OnCallConfig, fallback *time.Location) (*plan, error) {
+	p := &plan{loc: fallback, rotations: make(map[string][]string)}
+	if tz := strings.TrimSpace(cfg.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("oncall.timezone is invalid: %w", err)
+		}
+		p.loc = loc
+	}
+	if p.loc == nil {
+		p.loc = time.Local
+	}
+
+	for route, users := range cfg.Rotations {
+		var ids []string
+		for _, id := range users {
+			if id = strings.TrimSpace(id); id != "" {
+				ids = append(ids, id)
+			}
+		}
+		if len(ids) > 0 {
+			p.rotations[strings.ToLower(strings.TrimSpace(route))] = ids
+		}
+	}
+
+	if len(p.rotations) > 0 {
+		start, err := time.ParseInLocation(dateLayout, strings.TrimSpace(cfg.StartDate), p.loc)
+		if err != nil {
+			return nil, fmt.Errorf("oncall.start_date must be YYYY-MM-DD: %w", err)
+		}
+		handover := strings.TrimSpace(cfg.HandoverTime)
+		if handover == "" {
+			handover = "00:00"
+		}
+		clock, err := time.Parse(clockLayout, handover)
+		if err != nil {
+			return nil, fmt.Errorf("oncall.handover_time must be HH:MM: %w", err)
+		}
+		p.anchor = time.Date(start.Year(), start.Month(), start.Day(), clock.Hour(), clock.Minute(), 0, 0, p.loc)
+	}
+
+	for i, o := range cfg.Overrides {
+		user := strings.TrimSpace(o.User)
+		if user == "" {
+			return nil, fmt.Errorf("oncall.overrides[%d].user is required", i)
+		}
+		start, err := time.ParseInLocation(dateTimeLayout, strings.TrimSpace(o.Start), p.loc)
+		if err != nil {
+			return nil, fmt.Errorf("oncall.overrides[%d].start must be YYYY-MM-DD HH:MM: %w", i, err)
+		}
+		end, err := time.ParseInLocation(dateTimeLayout, strings.TrimSpace(o.End), p.loc)
+		if err != nil {
+			return nil, fmt.Errorf("oncall.overrides[%d].end must be YYYY-MM-DD HH:MM: %w", i, err)
+		}
+		if !end.After(start) {
+			return nil, fmt.Errorf("oncall.overrides[%d].end must be after start", i)
+		}
+		p.overrides = append(p.overrides, override{
+			route: strings.ToLower(strings.TrimSpace(o.Route)),
+			user:  user,
+			start: start,
+			end:   end,
+		})
+	}
+	return p, nil
+}
@@ -0,0 +1,104 @@
+package oncall
+
+import (
+	"testing"
+	"time"
+
+	"github.com/even/feishu-bot/internal/config"
+)
+
+func TestScheduleCurrent(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, loc)
+	}
+
+	// 2026-10-05 是周一，每周一 09:30 交接
+	s, err := New(config.OnCallConfig{
+		StartDate:    "2026-10-05",
+		HandoverTime: "09:30",
+		Rotations: map[string][]string{
+			"default": {"ou_a", "ou_b", "ou_c"},
+			"ring":    {"ou_r1", " ", "ou_r2"},
+		},
+		Overrides: []config.OnCallOverride{
+			{Route: "Ring", User: "ou_sub", Start: "2026-10-16 12:00", End: "2026-10-17 12:00"},
+			{User: "ou_all", Start: "2026-10-20 00:00", End: "2026-10-21 00:00"},
+		},
+	}, loc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		route    string
+		now      time.Time
+		user     string
+		until    time.Time
+		override bool
+	}{
+		{name: "first handover", route: "", now: at(10, 5, 9, 30), user: "ou_a", until: at(10, 12, 9, 30)},
+		{name: "just before handover", route: "", now: at(10, 12, 9, 29), user: "ou_a", until: at(10, 12, 9, 30)},
+		{name: "at handover", route: "", now: at(10, 12, 9, 30), user: "ou_b", until: at(10, 19, 9, 30)},
+		{name: "rotation wraps", route: "", now: at(10, 26, 10, 0), user: "ou_a", until: at(11, 2, 9, 30)},
+		{name: "before start date", route: "", now: at(10, 5, 9, 0), user: "ou_c", until: at(10, 5, 9, 30)},
+		{name: "route rotation skips blanks", route: "ring", now: at(10, 12, 10, 0), user: "ou_r2", until: at(10, 19, 9, 30)},
+		{name: "route name case-insensitive", route: "RING", now: at(10, 5, 10, 0), user: "ou_r1", until: at(10, 12, 9, 30)},
+		{name: "unknown route uses default", route: "glasses", now: at(10, 12, 10, 0), user: "ou_b", until: at(10, 19, 9, 30)},
+		{name: "route override", route: "ring", now: at(10, 16, 12, 0), user: "ou_sub", until: at(10, 17, 12, 0), override: true},
+		{name: "route override not for other routes", route: "", now: at(10, 16, 12, 0), user: "ou_b", until: at(10, 19, 9, 30)},
+		{name: "override end is exclusive", route: "ring", now: at(10, 17, 12, 0), user: "ou_r2", until: at(10, 19, 9, 30)},
+		{name: "global override", route: "ring", now: at(10, 20, 8, 0), user: "ou_all", until: at(10, 21, 0, 0), override: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := s.Current(tt.route, tt.now)
+			if got.User != tt.user || !got.Until.Equal(tt.until) || got.Override != tt.override {
+				t.Errorf("Current(%q, %s) = {%s, %s, %v}, want {%s, %s, %v}", tt.route, tt.now.Format(time.RFC3339),
+					got.User, got.Until.Format(time.RFC3339), got.Override, tt.user, tt.until.Format(time.RFC3339), tt.override)
+			}
+		})
+	}
+
+	empty, err := New(config.OnCallConfig{}, loc)
+	if err != nil {
+		t.Fatalf("New(empty): %v", err)
+	}
+	if got := empty.Current("ring", at(10, 16, 12, 0)); got.User != "" {
+		t.Errorf("empty schedule Current = %q, want no one", got.User)
+	}
+}
+
+func TestPlanWeekAcrossDST(t *testing.T) {
+	loc, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	// 2026-03-08 开始夏令时：交接时间仍为本地 09:00
+	p := &plan{loc: loc, anchor: time.Date(2026, 3, 2, 9, 0, 0, 0, loc)}
+
+	tests := []struct {
+		now   time.Time
+		week  int
+		until time.Time
+	}{
+		{now: time.Date(2026, 3, 9, 8, 59, 0, 0, loc), week: 0, until: time.Date(2026, 3, 9, 9, 0, 0, 0, loc)},
+		{now: time.Date(2026, 3, 9, 9, 0, 0, 0, loc), week: 1, until: time.Date(2026, 3, 16, 9, 0, 0, 0, loc)},
+		{now: time.Date(2026, 2, 28, 12, 0, 0, 0, loc), week: -1, until: time.Date(2026, 3, 2, 9, 0, 0, 0, loc)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.now.Format(time.RFC3339), func(t *testing.T) {
+			week, until := p.week(tt.now)
+			if week != tt.week || !until.Equal(tt.until) {
+				t.Errorf("week(%s) = %d, %s, want %d, %s", tt.now.Format(time.RFC3339),
+					week, until.Format(time.RFC3339), tt.week, tt.until.Format(time.RFC3339))
+			}
+		})
+	}
+}
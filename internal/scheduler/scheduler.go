@@ -0,0 +1,64 @@
+// Package scheduler 提供简单的周期性后台任务调度。
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// job 是一个周期性执行的任务。
+type job struct {
+	name     string
+	interval time.Duration
+	fn       func(ctx context.Context)
+}
+
+// Scheduler 按固定间隔运行后台任务，每个任务在独立的 goroutine 中串行执行。
+type Scheduler struct {
+	jobs []job
+}
+
+// New 创建新的调度器。
+func New() *Scheduler {
+	return &Scheduler{}
+}
+
+// Every 注册一个每隔 interval 执行一次的任务（需在 Start 之前调用）。
+func (s *Scheduler) Every(name string, interval time.Duration, fn func(ctx context.Context)) {
+	s.jobs = append(s.jobs, job{name: name, interval: interval, fn: fn})
+}
+
+// Start 启动所有任务，ctx 取消后停止。
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, j := range s.jobs {
+		log.Printf("[Scheduler] Starting job %s (every %s)", j.name, j.interval)
+		go s.run(ctx, j)
+	}
+}
+
+// run 循环执行单个任务。
+func (s *Scheduler) run(ctx context.Context, j job) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Printf("[Scheduler] Job %s stopped", j.name)
+			return
+		case <-ticker.C:
+			s.runOnce(ctx, j)
+		}
+	}
+}
+
+// runOnce 执行一次任务，捕获 panic 避免影响其他任务。
+func (s *Scheduler) runOnce(ctx context.Context, j job) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("[ERROR] Scheduler job %s panicked: %v", j.name, r)
+		}
+	}()
+	j.fn(ctx)
+}
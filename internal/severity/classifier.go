@@ -0,0 +1,69 @@
+// Package severity 提供转人工问题的严重程度分级：优先由 LLM 判断，
+// LLM 未启用或调用失败时按关键词规则分级。
+package severity
+
+import (
+	"context"
+	"log"
+
+	"github.com/even/feishu-bot/internal/config"
+	"github.com/even/feishu-bot/internal/llm"
+	"github.com/even/feishu-bot/pkg/models"
+)
+
+// 分级来源（记录日志）。
+const (
+	SourceLLM     = "llm"
+	SourceKeyword = "keyword"
+	SourceDefault = "default"
+)
+
+// Classifier 是严重程度分级器。
+type Classifier struct {
+	llm        llm.Client // 为 nil 时只按关键词规则分级
+	keywords   map[string][]string
+	fallback   models.Severity
+	priorities map[string]string
+}
+
+// New 根据配置创建分级器；client 为 nil 或配置未启用 LLM 时只按关键词规则分级。
+func New(cfg config.SeverityConfig, client llm.Client) *Classifier {
+	c := &Classifier{
+		keywords:   cfg.Keywords,
+		fallback:   models.Severity(cfg.Default),
+		priorities: cfg.Priorities,
+	}
+	if cfg.LLM {
+		c.llm = client
+	}
+	return c
+}
+
+// Classify 判断会话中提交的问题的严重程度，返回严重程度和分级来源；建议反馈不分级（返回空）。
+func (c *Classifier) Classify(ctx context.Context, conv *models.Conversation) (models.Severity, string) {
+	if conv.Mode == models.ModeSuggestion {
+		return "", ""
+	}
+
+	if c.llm != nil {
+		result, err := c.llm.ClassifySeverity(ctx, conv.GetInfoSummary())
+		if err == nil {
+			log.Printf("[Severity] Chat %s classified as %s by LLM: %s", conv.ChatID, result.Severity, result.Reason)
+			return result.Severity, SourceLLM
+		}
+		log.Printf("[Severity] LLM classification failed for chat %s, using keyword rules: %v", conv.ChatID, err)
+	}
+
+	for _, s := range models.Severities {
+		if words := c.keywords[string(s)]; len(words) > 0 && conv.ContainsAnyKeyword(words) {
+			log.Printf("[Severity] Chat %s classified as %s by keywords", conv.ChatID, s)
+			return s, SourceKeyword
+		}
+	}
+	return c.fallback, SourceDefault
+}
+
+// Priority 返回严重程度对应的工单初始优先级，未配置时为空。
+func (c *Classifier) Priority(s models.Severity) string {
+	return c.priorities[string(s)]
+}
@@ -0,0 +1,64 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+)
+
+const (
+	// AwayKey 是设置为离开的技术支持集合（open_id），自动分配时跳过。
+	AwayKey = "feishu:assign:away"
+	// RoundRobinKeyPrefix 是自动分配轮询计数器的前缀（feishu:assign:rr:{分配池}）。
+	RoundRobinKeyPrefix = "feishu:assign:rr:"
+)
+
+// SetAway 设置技术支持是否离开。
+func (s *Store) SetAway(ctx context.Context, userID string, away bool) error {
+	var err error
+	if away {
+		err = s.client.SAdd(ctx, AwayKey, userID).Err()
+	} else {
+		err = s.client.SRem(ctx, AwayKey, userID).Err()
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set away: %w", err)
+	}
+	return nil
+}
+
+// AwayUsers 返回设置为离开的技术支持。
+func (s *Store) AwayUsers(ctx context.Context) (map[string]bool, error) {
+	ids, err := s.client.SMembers(ctx, AwayKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get away users: %w", err)
+	}
+	away := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		away[id] = true
+	}
+	return away, nil
+}
+
+// NextRoundRobin 返回分配池的下一个轮询序号（从 0 开始递增）。
+func (s *Store) NextRoundRobin(ctx context.Context, pool string) (int64, error) {
+	n, err := s.client.Incr(ctx, RoundRobinKeyPrefix+pool).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to advance round robin: %w", err)
+	}
+	return n - 1, nil
+}
+
+// OpenAssignments 返回每位负责人当前未解决的工单数。
+func (s *Store) OpenAssignments(ctx context.Context) (map[string]int, error) {
+	tickets, err := s.ListOpen(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int)
+	for _, t := range tickets {
+		if t.Assignee != "" && !t.IsClosed() {
+			counts[t.Assignee]++
+		}
+	}
+	return counts, nil
+}
@@ -0,0 +1,106 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// CSATWeekKey 是按周统计满意度的哈希表（field 为 "{周}:sum" / "{周}:count"，周如 2026-W07）。
+	CSATWeekKey = "feishu:csat:week"
+	// CSATEngineerKey 是按处理人统计满意度的哈希表（field 为 "{open_id}:sum" / "{open_id}:count"）。
+	CSATEngineerKey = "feishu:csat:engineer"
+)
+
+// CSATScore 是某一周或某个处理人的满意度汇总。
+type CSATScore struct {
+	Key   string // 周或处理人 open_id
+	Sum   int64
+	Count int64
+}
+
+// Average 返回平均分。
+func (s CSATScore) Average() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Sum) / float64(s.Count)
+}
+
+// RecordCSAT 将一次评分计入所在周和处理人的汇总（engineer 为空时只计入周汇总）。
+func (s *Store) RecordCSAT(ctx context.Context, week, engineer string, rating int) error {
+	pipe := s.client.TxPipeline()
+	pipe.HIncrBy(ctx, CSATWeekKey, week+":sum", int64(rating))
+	pipe.HIncrBy(ctx, CSATWeekKey, week+":count", 1)
+	if engineer != "" {
+		pipe.HIncrBy(ctx, CSATEngineerKey, engineer+":sum", int64(rating))
+		pipe.HIncrBy(ctx, CSATEngineerKey, engineer+":count", 1)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to record csat: %w", err)
+	}
+	return nil
+}
+
+// CSATByWeek 返回按周的满意度汇总（按周倒序）。
+func (s *Store) CSATByWeek(ctx context.Context) ([]CSATScore, error) {
+	scores, err := s.csatScores(ctx, CSATWeekKey)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(scores, func(i, j int) bool { return scores[i].Key > scores[j].Key })
+	return scores, nil
+}
+
+// CSATByEngineer 返回按处理人的满意度汇总（按评价数倒序）。
+func (s *Store) CSATByEngineer(ctx context.Context) ([]CSATScore, error) {
+	scores, err := s.csatScores(ctx, CSATEngineerKey)
+	if err != nil {
+		return nil, err
+	}
+	sort.Slice(scores, func(i, j int) bool {
+		if scores[i].Count != scores[j].Count {
+			return scores[i].Count > scores[j].Count
+		}
+		return scores[i].Key < scores[j].Key
+	})
+	return scores, nil
+}
+
+// csatScores 读取汇总哈希表并按 key 合并 sum / count。
+func (s *Store) csatScores(ctx context.Context, key string) ([]CSATScore, error) {
+	fields, err := s.client.HGetAll(ctx, key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s: %w", key, err)
+	}
+
+	byKey := make(map[string]*CSATScore)
+	for field, val := range fields {
+		idx := strings.LastIndex(field, ":")
+		if idx < 0 {
+			continue
+		}
+		name, metric := field[:idx], field[idx+1:]
+		score, ok := byKey[name]
+		if !ok {
+			score = &CSATScore{Key: name}
+			byKey[name] = score
+		}
+		var n int64
+		fmt.Sscanf(val, "%d", &n)
+		switch metric {
+		case "sum":
+			score.Sum = n
+		case "count":
+			score.Count = n
+		}
+	}
+
+	scores := make([]CSATScore, 0, len(byKey))
+	for _, score := range byKey {
+		scores = append(scores, *score)
+	}
+	return scores, nil
+}
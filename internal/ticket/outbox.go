@@ -0,0 +1,99 @@
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// JobKeyPrefix 是转人工任务键的前缀（feishu:escalation:job:{id}）。
+	JobKeyPrefix = "feishu:escalation:job:"
+	// JobDueKey 是待执行转人工任务的有序集合（score 为下次执行时间戳）。
+	JobDueKey = "feishu:escalation:due"
+	// jobRetention 是转人工任务的保留时间（期间同一会话重复提交不会重复发送摘要）。
+	jobRetention = 7 * 24 * time.Hour
+)
+
+// SaveJob 保存转人工任务；待执行的任务按 NextAttemptAt 加入待执行集合，其余从集合中移除。
+func (s *Store) SaveJob(ctx context.Context, job *models.EscalationJob) error {
+	job.UpdatedAt = time.Now()
+	data, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("failed to marshal escalation job: %w", err)
+	}
+
+	// 暂缓到上班时间的任务至少保留到执行之后
+	ttl := jobRetention
+	if until := time.Until(job.NextAttemptAt) + jobRetention; until > ttl {
+		ttl = until
+	}
+
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, JobKeyPrefix+job.ID, data, ttl)
+	if job.Status == models.EscalationPending && !job.NextAttemptAt.IsZero() {
+		pipe.ZAdd(ctx, JobDueKey, redis.Z{Score: float64(job.NextAttemptAt.Unix()), Member: job.ID})
+	} else {
+		pipe.ZRem(ctx, JobDueKey, job.ID)
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save escalation job: %w", err)
+	}
+	return nil
+}
+
+// GetJob 获取转人工任务，不存在时返回 nil。
+func (s *Store) GetJob(ctx context.Context, id string) (*models.EscalationJob, error) {
+	data, err := s.client.Get(ctx, JobKeyPrefix+id).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get escalation job: %w", err)
+	}
+
+	var job models.EscalationJob
+	if err := json.Unmarshal(data, &job); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal escalation job: %w", err)
+	}
+	return &job, nil
+}
+
+// DeleteJob 删除转人工任务（含待执行集合中的记录）。
+func (s *Store) DeleteJob(ctx context.Context, id string) error {
+	pipe := s.client.TxPipeline()
+	pipe.Del(ctx, JobKeyPrefix+id)
+	pipe.ZRem(ctx, JobDueKey, id)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to delete escalation job: %w", err)
+	}
+	return nil
+}
+
+// ClaimDueJobs 取出已到执行时间的转人工任务 ID。
+// 每个任务通过 ZREM 原子认领，多实例部署时只有一个实例会处理。
+func (s *Store) ClaimDueJobs(ctx context.Context, now time.Time) ([]string, error) {
+	ids, err := s.client.ZRangeByScore(ctx, JobDueKey, &redis.ZRangeBy{
+		Min: "-inf",
+		Max: fmt.Sprintf("%d", now.Unix()),
+	}).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list due escalation jobs: %w", err)
+	}
+
+	var claimed []string
+	for _, id := range ids {
+		n, err := s.client.ZRem(ctx, JobDueKey, id).Result()
+		if err != nil {
+			return claimed, fmt.Errorf("failed to claim escalation job: %w", err)
+		}
+		if n > 0 {
+			claimed = append(claimed, id)
+		}
+	}
+	return claimed, nil
+}
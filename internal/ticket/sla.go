@@ -0,0 +1,42 @@
+package ticket
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// SLABreachKey 是 SLA 超时计数的哈希表（field 为 "{考核项}:{优先级}"）。
+const SLABreachKey = "feishu:sla:breaches"
+
+// IncrSLABreach 将指定考核项和优先级的 SLA 超时计数加一。
+func (s *Store) IncrSLABreach(ctx context.Context, kind, priority string) error {
+	if err := s.client.HIncrBy(ctx, SLABreachKey, kind+":"+priority, 1).Err(); err != nil {
+		return fmt.Errorf("failed to count sla breach: %w", err)
+	}
+	return nil
+}
+
+// SLABreach 是某个考核项和优先级的累计超时次数。
+type SLABreach struct {
+	Kind     string
+	Priority string
+	Count    int64
+}
+
+// SLABreaches 返回累计的 SLA 超时计数。
+func (s *Store) SLABreaches(ctx context.Context) ([]SLABreach, error) {
+	counts, err := s.client.HGetAll(ctx, SLABreachKey).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sla breaches: %w", err)
+	}
+
+	breaches := make([]SLABreach, 0, len(counts))
+	for field, val := range counts {
+		kind, priority, _ := strings.Cut(field, ":")
+		var count int64
+		fmt.Sscanf(val, "%d", &count)
+		breaches = append(breaches, SLABreach{Kind: kind, Priority: priority, Count: count})
+	}
+	return breaches, nil
+}
@@ -0,0 +1,202 @@
+// Package ticket 提供使用 Redis 的工单持久化与查询。
+package ticket
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/pkg/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// TicketKeyPrefix 是 Redis 中工单键的前缀（feishu:ticket:{id}）。
+	TicketKeyPrefix = "feishu:ticket:"
+	// SequenceKey 是生成工单号的自增计数器。
+	SequenceKey = "feishu:ticket:seq"
+	// UserIndexKeyPrefix 是用户工单索引（有序集合，score 为创建时间戳）的前缀。
+	UserIndexKeyPrefix = "feishu:ticket:user:"
+	// SNIndexKeyPrefix 是序列号工单索引（有序集合，score 为创建时间戳）的前缀。
+	SNIndexKeyPrefix = "feishu:ticket:sn:"
+	// RootIndexKeyPrefix 是话题根消息 ID → 工单号映射的前缀。
+	RootIndexKeyPrefix = "feishu:ticket:root:"
+	// ActiveKeyPrefix 是用户私聊 chatID → 正在沟通的工单号的前缀（带过期时间）。
+	ActiveKeyPrefix = "feishu:ticket:active:"
+	// OpenIndexKey 是未解决工单的有序集合（score 为创建时间戳），供 SLA 检查遍历。
+	OpenIndexKey = "feishu:ticket:open"
+)
+
+// idPrefix 是工单号前缀，工单号格式为 T + 6 位序号（如 T000123）。
+const idPrefix = "T"
+
+// Store 使用 Redis 处理工单持久化（工单不设过期时间）。
+type Store struct {
+	client *redis.Client
+}
+
+// NewStore 创建新的 Redis 支持的工单存储（与会话存储共用连接）。
+func NewStore(client *redis.Client) *Store {
+	return &Store{client: client}
+}
+
+// NextID 生成新的工单号。
+func (s *Store) NextID(ctx context.Context) (string, error) {
+	seq, err := s.client.Incr(ctx, SequenceKey).Result()
+	if err != nil {
+		return "", fmt.Errorf("failed to generate ticket id: %w", err)
+	}
+	return formatID(seq), nil
+}
+
+// Save 保存工单并更新用户、序列号和话题根消息索引。
+func (s *Store) Save(ctx context.Context, t *models.Ticket) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("failed to marshal ticket: %w", err)
+	}
+
+	score := float64(t.CreatedAt.Unix())
+	pipe := s.client.TxPipeline()
+	pipe.Set(ctx, s.ticketKey(t.ID), data, 0)
+	if t.UserID != "" {
+		pipe.ZAdd(ctx, UserIndexKeyPrefix+t.UserID, redis.Z{Score: score, Member: t.ID})
+	}
+	for _, sn := range t.SerialNumbers() {
+		pipe.ZAdd(ctx, SNIndexKeyPrefix+sn, redis.Z{Score: score, Member: t.ID})
+	}
+	if t.RootMsgID != "" {
+		pipe.Set(ctx, RootIndexKeyPrefix+t.RootMsgID, t.ID, 0)
+	}
+	if t.IsClosed() {
+		pipe.ZRem(ctx, OpenIndexKey, t.ID)
+	} else {
+		pipe.ZAdd(ctx, OpenIndexKey, redis.Z{Score: score, Member: t.ID})
+	}
+	if _, err := pipe.Exec(ctx); err != nil {
+		return fmt.Errorf("failed to save ticket: %w", err)
+	}
+	return nil
+}
+
+// Get 根据工单号获取工单，不存在时返回 nil。
+func (s *Store) Get(ctx context.Context, id string) (*models.Ticket, error) {
+	data, err := s.client.Get(ctx, s.ticketKey(id)).Bytes()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ticket: %w", err)
+	}
+
+	var t models.Ticket
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ticket: %w", err)
+	}
+	return &t, nil
+}
+
+// GetByRootMessage 根据转人工群组中的话题根消息 ID 获取工单，不存在时返回 nil。
+func (s *Store) GetByRootMessage(ctx context.Context, rootMsgID string) (*models.Ticket, error) {
+	id, err := s.client.Get(ctx, RootIndexKeyPrefix+rootMsgID).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get ticket by root message: %w", err)
+	}
+	return s.Get(ctx, id)
+}
+
+// ListByUser 返回用户最近的工单（按创建时间倒序，limit 不大于 0 时返回全部）。
+func (s *Store) ListByUser(ctx context.Context, userID string, limit int) ([]*models.Ticket, error) {
+	return s.listIndex(ctx, UserIndexKeyPrefix+userID, limit)
+}
+
+// ListBySN 返回序列号相关的工单（按创建时间倒序，limit 不大于 0 时返回全部）。
+func (s *Store) ListBySN(ctx context.Context, sn string, limit int) ([]*models.Ticket, error) {
+	return s.listIndex(ctx, SNIndexKeyPrefix+models.NormalizeSN(sn), limit)
+}
+
+// ListOpen 返回全部未解决的工单（按创建时间倒序）。
+func (s *Store) ListOpen(ctx context.Context) ([]*models.Ticket, error) {
+	return s.listIndex(ctx, OpenIndexKey, 0)
+}
+
+// listIndex 按索引有序集合倒序读取工单，忽略已不存在的工单。
+func (s *Store) listIndex(ctx context.Context, key string, limit int) ([]*models.Ticket, error) {
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(limit - 1)
+	}
+	ids, err := s.client.ZRevRange(ctx, key, 0, stop).Result()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list %s: %w", key, err)
+	}
+
+	tickets := make([]*models.Ticket, 0, len(ids))
+	for _, id := range ids {
+		t, err := s.Get(ctx, id)
+		if err != nil {
+			return tickets, err
+		}
+		if t != nil {
+			tickets = append(tickets, t)
+		}
+	}
+	return tickets, nil
+}
+
+// SetActive 将工单设为用户私聊中正在沟通的工单，window 内用户的消息会转发到工单话题。
+func (s *Store) SetActive(ctx context.Context, chatID, id string, window time.Duration) error {
+	if err := s.client.Set(ctx, ActiveKeyPrefix+chatID, id, window).Err(); err != nil {
+		return fmt.Errorf("failed to set active ticket: %w", err)
+	}
+	return nil
+}
+
+// GetActive 返回用户私聊中正在沟通的工单，没有或已过期时返回 nil。
+func (s *Store) GetActive(ctx context.Context, chatID string) (*models.Ticket, error) {
+	id, err := s.client.Get(ctx, ActiveKeyPrefix+chatID).Result()
+	if err != nil {
+		if err == redis.Nil {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get active ticket: %w", err)
+	}
+	return s.Get(ctx, id)
+}
+
+// ClearActive 结束用户私聊与工单话题的转发。
+func (s *Store) ClearActive(ctx context.Context, chatID string) error {
+	if err := s.client.Del(ctx, ActiveKeyPrefix+chatID).Err(); err != nil {
+		return fmt.Errorf("failed to clear active ticket: %w", err)
+	}
+	return nil
+}
+
+// ticketKey 返回工单的 Redis 键。
+func (s *Store) ticketKey(id string) string {
+	return TicketKeyPrefix + id
+}
+
+// formatID 将序号格式化为工单号。
+func formatID(seq int64) string {
+	return fmt.Sprintf("%s%06d", idPrefix, seq)
+}
+
+// ParseID 将用户输入解析为工单号，支持 "T000123"、"t123"、"#T123"、"#123" 等写法。
+func ParseID(s string) (string, bool) {
+	s = strings.TrimPrefix(strings.TrimSpace(s), "#")
+	if len(s) > 0 && (s[0] == 'T' || s[0] == 't') {
+		s = s[1:]
+	}
+	seq, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || seq <= 0 {
+		return "", false
+	}
+	return formatID(seq), true
+}
@@ -0,0 +1,198 @@
+// Package workhours 提供技术支持团队的工作时间日历：每周工作日和上下班时间，
+// 加上节假日和调休上班日。
+package workhours
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/even/feishu-bot/internal/config"
+)
+
+// 日历中的日期 / 时间格式（工作时间时区）。
+const (
+	dateLayout  = "2006-01-02"
+	clockLayout = "15:04"
+)
+
+// searchDays 是查找下一个上班时间时最多向后查找的天数。
+const searchDays = 366
+
+// weekdayNames 是 weekdays 配置中星期的写法。
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// zhWeekdays 是星期的中文显示。
+var zhWeekdays = [...]string{"周日", "周一", "周二", "周三", "周四", "周五", "周六"}
+
+// Calendar 是工作时间日历；未配置上下班时间时视为全天工作（IsOpen 始终为 true）。
+type Calendar struct {
+	loc           *time.Location
+	weekdays      map[time.Weekday]bool
+	start, end    int // 上下班时间（当天第几分钟）
+	holidays      map[string]bool
+	workdays      map[string]bool
+	firstResponse time.Duration
+	hold          bool
+}
+
+// New 根据配置创建日历；fallback 是未配置 timezone 时使用的时区。
+func New(cfg config.BusinessHoursConfig, fallback *time.Location) (*Calendar, error) {
+	c := &Calendar{
+		loc:           fallback,
+		weekdays:      make(map[time.Weekday]bool),
+		holidays:      make(map[string]bool),
+		workdays:      make(map[string]bool),
+		firstResponse: time.Duration(cfg.FirstResponse) * time.Minute,
+		hold:          cfg.Hold,
+	}
+	if tz := strings.TrimSpace(cfg.Timezone); tz != "" {
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			return nil, fmt.Errorf("business_hours.timezone is invalid: %w", err)
+		}
+		c.loc = loc
+	}
+	if c.loc == nil {
+		c.loc = time.Local
+	}
+
+	start, end := strings.TrimSpace(cfg.Start), strings.TrimSpace(cfg.End)
+	if start == "" && end == "" {
+		return c, nil
+	}
+	var err error
+	if c.start, err = parseClock(start); err != nil {
+		return nil, fmt.Errorf("business_hours.start must be HH:MM: %w", err)
+	}
+	if c.end, err = parseClock(end); err != nil {
+		return nil, fmt.Errorf("business_hours.end must be HH:MM: %w", err)
+	}
+	if c.end <= c.start {
+		return nil, fmt.Errorf("business_hours.end must be after start")
+	}
+	if cfg.FirstResponse < 0 {
+		return nil, fmt.Errorf("business_hours.first_response must not be negative")
+	}
+
+	weekdays := cfg.Weekdays
+	if len(weekdays) == 0 {
+		weekdays = []string{"mon", "tue", "wed", "thu", "fri"}
+	}
+	for _, name := range weekdays {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if len(key) > 3 {
+			key = key[:3] // monday → mon
+		}
+		day, ok := weekdayNames[key]
+		if !ok {
+			return nil, fmt.Errorf("business_hours.weekdays: unknown weekday %q", name)
+		}
+		c.weekdays[day] = true
+	}
+	for _, d := range cfg.Holidays {
+		key, err := parseDate(d, c.loc)
+		if err != nil {
+			return nil, fmt.Errorf("business_hours.holidays: %w", err)
+		}
+		c.holidays[key] = true
+	}
+	for _, d := range cfg.Workdays {
+		key, err := parseDate(d, c.loc)
+		if err != nil {
+			return nil, fmt.Errorf("business_hours.workdays: %w", err)
+		}
+		c.workdays[key] = true
+	}
+	return c, nil
+}
+
+// Enabled 判断是否配置了工作时间。
+func (c *Calendar) Enabled() bool {
+	return c.end > 0
+}
+
+// Hold 判断非工作时间是否暂缓非紧急问题。
+func (c *Calendar) Hold() bool {
+	return c.Enabled() && c.hold
+}
+
+// IsOpen 判断 t 是否在工作时间内。
+func (c *Calendar) IsOpen(t time.Time) bool {
+	if !c.Enabled() {
+		return true
+	}
+	t = t.In(c.loc)
+	minute := t.Hour()*60 + t.Minute()
+	return c.isWorkday(t) && minute >= c.start && minute < c.end
+}
+
+// NextOpen 返回 t 之后（含 t）最近的工作时间；一年内没有工作日时返回零值。
+func (c *Calendar) NextOpen(t time.Time) time.Time {
+	if c.IsOpen(t) {
+		return t
+	}
+	t = t.In(c.loc)
+	for i := 0; i < searchDays; i++ {
+		d := t.AddDate(0, 0, i)
+		if !c.isWorkday(d) {
+			continue
+		}
+		open := time.Date(d.Year(), d.Month(), d.Day(), c.start/60, c.start%60, 0, 0, c.loc)
+		if open.After(t) {
+			return open
+		}
+	}
+	return time.Time{}
+}
+
+// ExpectedResponse 返回在 t 提交的问题预计首次回复的时间（下一个上班时间 + first_response）；
+// 未配置 first_response 时返回零值。
+func (c *Calendar) ExpectedResponse(t time.Time) time.Time {
+	open := c.NextOpen(t)
+	if c.firstResponse == 0 || open.IsZero() {
+		return time.Time{}
+	}
+	return open.Add(c.firstResponse)
+}
+
+// Format 返回时间在工作时间时区的中英文显示（如 "10月19日 周一 09:00" / "Mon Oct 19 09:00"）。
+func (c *Calendar) Format(t time.Time) (zh, en string) {
+	t = t.In(c.loc)
+	zh = fmt.Sprintf("%d月%d日 %s %s", t.Month(), t.Day(), zhWeekdays[t.Weekday()], t.Format(clockLayout))
+	en = t.Format("Mon Jan 2 15:04")
+	return zh, en
+}
+
+// isWorkday 判断 day 所在日期（工作时间时区）是否上班：调休上班日优先，其次节假日，最后按每周工作日。
+func (c *Calendar) isWorkday(day time.Time) bool {
+	key := day.Format(dateLayout)
+	if c.workdays[key] {
+		return true
+	}
+	if c.holidays[key] {
+		return false
+	}
+	return c.weekdays[day.Weekday()]
+}
+
+// parseClock 将 "09:00" 解析为当天第几分钟。
+func parseClock(s string) (int, error) {
+	clock, err := time.Parse(clockLayout, s)
+	if err != nil {
+		return 0, err
+	}
+	return clock.Hour()*60 + clock.Minute(), nil
+}
+
+// parseDate 校验日期并返回标准写法。
+func parseDate(s string, loc *time.Location) (string, error) {
+	d, err := time.ParseInLocation(dateLayout, strings.TrimSpace(s), loc)
+	if err != nil {
+		return "", fmt.Errorf("%q must be YYYY-MM-DD", s)
+	}
+	return d.Format(dateLayout), nil
+}
@@ -0,0 +1,77 @@
+package workhours
+
+import (
+	"testing"
+	"time"
+
+	"github.com/even/feishu-bot/internal/config"
+)
+
+func TestCalendar(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+	at := func(month time.Month, day, hour, minute int) time.Time {
+		return time.Date(2026, month, day, hour, minute, 0, 0, loc)
+	}
+
+	// 2026-10-16 是周五；10-19（周一）放假，10-24（周六）调休上班
+	c, err := New(config.BusinessHoursConfig{
+		Start:         "09:00",
+		End:           "18:00",
+		Holidays:      []string{"2026-10-19"},
+		Workdays:      []string{"2026-10-24"},
+		FirstResponse: 60,
+	}, loc)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		open     bool
+		nextOpen time.Time
+	}{
+		{name: "working hours", now: at(10, 16, 10, 0), open: true, nextOpen: at(10, 16, 10, 0)},
+		{name: "last minute", now: at(10, 16, 17, 59), open: true, nextOpen: at(10, 16, 17, 59)},
+		{name: "other time zone", now: time.Date(2026, 10, 16, 2, 0, 0, 0, time.UTC), open: true, nextOpen: at(10, 16, 10, 0)},
+		{name: "before opening", now: at(10, 16, 7, 0), nextOpen: at(10, 16, 9, 0)},
+		{name: "closing time", now: at(10, 16, 18, 0), nextOpen: at(10, 20, 9, 0)},
+		{name: "after closing rolls over weekend and holiday", now: at(10, 16, 19, 0), nextOpen: at(10, 20, 9, 0)},
+		{name: "after midnight", now: at(10, 15, 23, 30), nextOpen: at(10, 16, 9, 0)},
+		{name: "weekend", now: at(10, 17, 10, 0), nextOpen: at(10, 20, 9, 0)},
+		{name: "holiday on a weekday", now: at(10, 19, 10, 0), nextOpen: at(10, 20, 9, 0)},
+		{name: "make-up workday", now: at(10, 24, 10, 0), open: true, nextOpen: at(10, 24, 10, 0)},
+		{name: "next day is a make-up workday", now: at(10, 23, 18, 30), nextOpen: at(10, 24, 9, 0)},
+		{name: "after make-up workday", now: at(10, 24, 18, 0), nextOpen: at(10, 26, 9, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := c.IsOpen(tt.now); got != tt.open {
+				t.Errorf("IsOpen(%s) = %v, want %v", tt.now.Format(time.RFC3339), got, tt.open)
+			}
+			if got := c.NextOpen(tt.now); !got.Equal(tt.nextOpen) {
+				t.Errorf("NextOpen(%s) = %s, want %s", tt.now.Format(time.RFC3339), got.Format(time.RFC3339), tt.nextOpen.Format(time.RFC3339))
+			}
+		})
+	}
+
+	if got, want := c.ExpectedResponse(at(10, 16, 19, 0)), at(10, 20, 10, 0); !got.Equal(want) {
+		t.Errorf("ExpectedResponse = %s, want %s", got.Format(time.RFC3339), want.Format(time.RFC3339))
+	}
+}
+
+func TestCalendarDisabled(t *testing.T) {
+	c, err := New(config.BusinessHoursConfig{Hold: true}, time.UTC)
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	now := time.Date(2026, 10, 17, 3, 0, 0, 0, time.UTC)
+	if !c.IsOpen(now) || !c.NextOpen(now).Equal(now) || c.Hold() {
+		t.Errorf("disabled calendar: IsOpen = %v, NextOpen = %s, Hold = %v, want always open without hold",
+			c.IsOpen(now), c.NextOpen(now).Format(time.RFC3339), c.Hold())
+	}
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// EscalationJobStatus 表示转人工任务的状态。
+type EscalationJobStatus string
+
+const (
+	EscalationPending EscalationJobStatus = "pending" // 有未完成的步骤，等待执行 / 重试
+	EscalationDone    EscalationJobStatus = "done"    // 全部步骤已完成
+	EscalationFailed  EscalationJobStatus = "failed"  // 重试次数用尽
+)
+
+// EscalationJob 是持久化的转人工任务：记录每个步骤的进度，失败后可按退避时间重试，
+// 进程重启后可继续执行。同一会话只对应一个任务，保证摘要最多发送一次。
+type EscalationJob struct {
+	ID           string              `json:"id"`
+	Conversation *Conversation       `json:"conversation"` // 提交时的会话快照
+	Status       EscalationJobStatus `json:"status"`
+
+	// 提交时的自动分级（转人工前完成，重试时不再重新分级）
+	Severity Severity `json:"severity,omitempty"`
+	Priority string   `json:"priority,omitempty"` // 按严重程度配置的初始优先级
+
+	// 非工作时间暂缓的任务：上班时间到达前不发送摘要（由后台任务在上班时继续执行）
+	HeldUntil    time.Time `json:"held_until,omitempty"`
+	HoldNotified bool      `json:"hold_notified,omitempty"` // 已告知用户暂缓
+	QueuedSaved  bool      `json:"queued_saved,omitempty"`  // 已保存暂缓中的工单（状态为 queued，可查询）
+
+	// 各步骤进度
+	Target      *RouteTarget `json:"target,omitempty"`       // 已选择的路由（目标群组、标题、额外 @ 的人）
+	Invited     bool         `json:"invited,omitempty"`      // 已邀请用户入群（失败不重试）
+	TicketID    string       `json:"ticket_id,omitempty"`    // 已生成工单号
+	Assigned    bool         `json:"assigned,omitempty"`     // 已自动分配（无人可分配时 Assignee 为空，不重试）
+	Assignee    string       `json:"assignee,omitempty"`     // 自动分配的负责人 open_id
+	RootMsgID   string       `json:"root_msg_id,omitempty"`  // 摘要已发送（话题根消息）
+	Pinned      bool         `json:"pinned,omitempty"`       // 紧急问题已置顶摘要（失败不重试）
+	TicketSaved bool         `json:"ticket_saved,omitempty"` // 工单已保存
+	FilesSent   []string     `json:"files_sent,omitempty"`   // 已转发到话题的文件 fileKey
+	Notified    bool         `json:"notified,omitempty"`     // 已通知用户
+
+	// 重试状态
+	Attempts      int       `json:"attempts,omitempty"`
+	LastError     string    `json:"last_error,omitempty"`
+	NextAttemptAt time.Time `json:"next_attempt_at,omitempty"` // 下次执行时间（执行中为租约到期时间）
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// EscalationJobID 返回会话对应的转人工任务 ID（同一会话多次提交得到同一个任务）。
+func EscalationJobID(conv *Conversation) string {
+	return fmt.Sprintf("%s:%d", conv.ChatID, conv.CreatedAt.UnixNano())
+}
+
+// NewEscalationJob 根据会话创建待执行的转人工任务。
+func NewEscalationJob(conv *Conversation) *EscalationJob {
+	now := time.Now()
+	return &EscalationJob{
+		ID:           EscalationJobID(conv),
+		Conversation: conv,
+		Status:       EscalationPending,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+}
+
+// SummaryPosted 判断摘要是否已发送到群组。
+func (j *EscalationJob) SummaryPosted() bool {
+	return j.RootMsgID != ""
+}
+
+// Held 判断任务在 now 时刻是否仍处于暂缓中（摘要未发送且未到上班时间）。
+func (j *EscalationJob) Held(now time.Time) bool {
+	return !j.SummaryPosted() && now.Before(j.HeldUntil)
+}
+
+// FileSent 判断文件是否已转发到话题。
+func (j *EscalationJob) FileSent(fileKey string) bool {
+	for _, k := range j.FilesSent {
+		if k == fileKey {
+			return true
+		}
+	}
+	return false
+}
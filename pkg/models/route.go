@@ -0,0 +1,154 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+// EscalationRoute 定义一条转人工路由规则（由 config.yaml 的 routes 配置加载）。
+// 规则按配置顺序匹配，第一条命中的规则决定目标群组、话题标题和额外 @ 的人；
+// 都不命中时发往 feishu.escalation_group_id。
+type EscalationRoute struct {
+	Name     string   `mapstructure:"name"`     // 路由名称（日志、预览和工单记录中显示）
+	GroupID  string   `mapstructure:"group_id"` // 目标话题群 chat_id
+	Title    string   `mapstructure:"title"`    // 话题标题（后接工单号），空时按模式使用默认标题
+	Mentions []string `mapstructure:"mentions"` // 摘要中额外 @ 的人（open_id）
+
+	// 匹配条件：配置的各项需同时满足，未配置的项不限制
+	Mode     ConversationMode `mapstructure:"mode"`     // issue / suggestion
+	Fields   []FieldCondition `mapstructure:"fields"`   // 字段条件（与 required_if 相同写法），需全部满足
+	Versions []VersionRange   `mapstructure:"versions"` // 版本范围，需全部满足
+	Keywords []string         `mapstructure:"keywords"` // 用户填写的内容包含其中任一关键词
+	Users    []string         `mapstructure:"users"`    // 提交用户的 open_id 为其中之一
+}
+
+// VersionRange 定义版本字段的范围条件：Min <= 版本 < Max，未配置的一端不限制。
+type VersionRange struct {
+	Field string `mapstructure:"field"` // 版本字段 key（如 app_version）
+	Min   string `mapstructure:"min"`   // 最低版本（含）
+	Max   string `mapstructure:"max"`   // 最高版本（不含）
+}
+
+// RouteTarget 是路由结果，保存在转人工任务中（重试时发往同一群组）。
+type RouteTarget struct {
+	Route    string   `json:"route,omitempty"` // 命中的路由名称，空表示默认群组
+	GroupID  string   `json:"group_id"`
+	Title    string   `json:"title,omitempty"`
+	Mentions []string `json:"mentions,omitempty"`
+	OnCall   string   `json:"oncall,omitempty"` // 路由时的值班人员
+}
+
+// Target 返回规则命中时的路由结果。
+func (r EscalationRoute) Target() RouteTarget {
+	return RouteTarget{
+		Route:    r.Name,
+		GroupID:  r.GroupID,
+		Title:    r.Title,
+		Mentions: append([]string(nil), r.Mentions...),
+	}
+}
+
+// Matches 判断会话是否满足规则的全部条件。
+func (r EscalationRoute) Matches(c *Conversation) bool {
+	if r.Mode != ModeUnknown && c.Mode != r.Mode {
+		return false
+	}
+	for _, cond := range r.Fields {
+		if !c.matchCondition(cond) {
+			return false
+		}
+	}
+	for _, v := range r.Versions {
+		if !c.matchVersionRange(v) {
+			return false
+		}
+	}
+	if len(r.Keywords) > 0 && !c.ContainsAnyKeyword(r.Keywords) {
+		return false
+	}
+	if len(r.Users) > 0 && !containsFold(r.Users, c.SenderID) {
+		return false
+	}
+	return true
+}
+
+// matchVersionRange 判断版本字段（优先取标准值）是否在范围内；字段未填写或无法解析时不满足。
+func (c *Conversation) matchVersionRange(v VersionRange) bool {
+	val := c.NormalizedInfo[v.Field]
+	if val == "" {
+		val = strings.TrimSpace(c.CollectedInfo[v.Field])
+	}
+	if !IsValidVersion(val) {
+		return false
+	}
+	if v.Min != "" && CompareVersions(val, v.Min) < 0 {
+		return false
+	}
+	if v.Max != "" && CompareVersions(val, v.Max) >= 0 {
+		return false
+	}
+	return true
+}
+
+// ContainsAnyKeyword 判断用户填写的内容（各字段原文和建议内容）是否包含任一关键词。
+func (c *Conversation) ContainsAnyKeyword(keywords []string) bool {
+	texts := []string{c.SuggestionText}
+	for _, f := range allFields {
+		texts = append(texts, c.CollectedInfo[f.Key])
+	}
+	for _, text := range texts {
+		for _, kw := range keywords {
+			if ContainsKeyword(text, kw) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// CompareVersions 按数字逐段比较版本号（忽略 "v" 前缀，缺少的段视为 0），
+// a < b 返回 -1，相等返回 0，a > b 返回 1；无法解析的版本视为最小。
+func CompareVersions(a, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// IsValidVersion 判断字符串是否为可比较的版本号。
+func IsValidVersion(s string) bool {
+	_, ok := parseVersion(s)
+	return ok
+}
+
+// parseVersion 将 "v2.0.6" 这类版本号解析为各段数字。
+func parseVersion(s string) ([]int, bool) {
+	s = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(s)), "v")
+	if s == "" {
+		return nil, false
+	}
+	parts := strings.Split(s, ".")
+	nums := make([]int, 0, len(parts))
+	for _, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, false
+		}
+		nums = append(nums, n)
+	}
+	return nums, true
+}
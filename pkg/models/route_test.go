@@ -0,0 +1,101 @@
+package models
+
+import "testing"
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"2.0.6", "2.0.6", 0},
+		{"v2.0.6", "2.0.6", 0},
+		{"2.1", "2.1.0", 0},
+		{"2.0.10", "2.0.9", 1},
+		{"2.0.9", "2.1.0", -1},
+		{"10.0.0", "9.9.9", 1},
+		{"1.2.3.4567", "1.2.3", 1},
+		{"latest", "0.0.1", -1},
+		{"", "", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.a+" vs "+tt.b, func(t *testing.T) {
+			if got := CompareVersions(tt.a, tt.b); got != tt.want {
+				t.Errorf("CompareVersions(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRouteMatches(t *testing.T) {
+	SetFieldSchema([]FieldDef{
+		{Key: "issue", Name: "问题描述 / Issue Description", ShortName: "问题描述"},
+		{Key: "app_version", Name: "App 版本 / App Version", ShortName: "App版本"},
+		{Key: "ring_version", Name: "戒指版本 / Ring Firmware", ShortName: "戒指版本"},
+	})
+	defer SetFieldSchema(nil)
+
+	present := true
+	absent := false
+	display := EscalationRoute{
+		Name:     "glasses-display-2.0",
+		Keywords: []string{"显示", "display"},
+		Versions: []VersionRange{{Field: "app_version", Min: "2.0.0", Max: "2.1.0"}},
+	}
+	ring := EscalationRoute{
+		Name:   "ring",
+		Mode:   ModeIssue,
+		Fields: []FieldCondition{{Field: "ring_version", Present: &present}},
+	}
+	noRing := EscalationRoute{
+		Name:   "glasses-only",
+		Fields: []FieldCondition{{Field: "ring_version", Present: &absent}},
+	}
+	vip := EscalationRoute{Name: "vip", Users: []string{"ou_VIP"}}
+
+	conv := func(mode ConversationMode, info map[string]string) *Conversation {
+		return &Conversation{Mode: mode, SenderID: "ou_user", CollectedInfo: info, NormalizedInfo: map[string]string{}}
+	}
+
+	tests := []struct {
+		name  string
+		route EscalationRoute
+		conv  *Conversation
+		want  bool
+	}{
+		{"version at min", display, conv(ModeIssue, map[string]string{"issue": "显示异常", "app_version": "2.0.0"}), true},
+		{"version inside range", display, conv(ModeIssue, map[string]string{"issue": "display flickers", "app_version": "v2.0.9"}), true},
+		{"version at max is excluded", display, conv(ModeIssue, map[string]string{"issue": "显示异常", "app_version": "2.1"}), false},
+		{"version below min", display, conv(ModeIssue, map[string]string{"issue": "显示异常", "app_version": "1.9.9"}), false},
+		{"version missing", display, conv(ModeIssue, map[string]string{"issue": "显示异常"}), false},
+		{"version unparseable", display, conv(ModeIssue, map[string]string{"issue": "显示异常", "app_version": "最新版"}), false},
+		{"keyword missing", display, conv(ModeIssue, map[string]string{"issue": "蓝牙断开", "app_version": "2.0.5"}), false},
+		{
+			"normalized version preferred",
+			display,
+			&Conversation{
+				CollectedInfo:  map[string]string{"issue": "显示异常", "app_version": "App 2.0"},
+				NormalizedInfo: map[string]string{"app_version": "2.0.0"},
+			},
+			true,
+		},
+
+		{"field present", ring, conv(ModeIssue, map[string]string{"ring_version": "1.2.0"}), true},
+		{"field present wrong mode", ring, conv(ModeSuggestion, map[string]string{"ring_version": "1.2.0"}), false},
+		{"field absent", ring, conv(ModeIssue, map[string]string{"issue": "戒指连不上"}), false},
+		{"present false matches empty", noRing, conv(ModeIssue, map[string]string{"ring_version": " "}), true},
+		{"present false rejects value", noRing, conv(ModeIssue, map[string]string{"ring_version": "1.2.0"}), false},
+
+		{"user case-insensitive", vip, &Conversation{SenderID: "ou_vip"}, true},
+		{"other user", vip, conv(ModeIssue, nil), false},
+		{"no conditions", EscalationRoute{Name: "all"}, conv(ModeSuggestion, nil), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.route.Matches(tt.conv); got != tt.want {
+				t.Errorf("route %q Matches = %v, want %v", tt.route.Name, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+package models
+
+import "strings"
+
+// Severity 表示转人工问题的严重程度（提交时自动分级）。
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical" // 紧急：安全风险（过热、冒烟、灼伤等）或大面积不可用
+	SeverityHigh     Severity = "high"     // 严重：核心功能不可用，无法绕过
+	SeverityMedium   Severity = "medium"   // 一般：功能异常但可绕过
+	SeverityLow      Severity = "low"      // 轻微：体验问题、咨询
+)
+
+// Severities 是全部严重程度（从高到低），关键词规则按此顺序匹配。
+var Severities = []Severity{SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow}
+
+// severityNames 是严重程度的中文名称（话题标题中显示）。
+var severityNames = map[Severity]string{
+	SeverityCritical: "紧急",
+	SeverityHigh:     "严重",
+	SeverityMedium:   "一般",
+	SeverityLow:      "轻微",
+}
+
+// ParseSeverity 将 "critical"、"紧急" 等写法解析为严重程度。
+func ParseSeverity(raw string) (Severity, bool) {
+	raw = strings.ToLower(strings.TrimSpace(raw))
+	for _, s := range Severities {
+		if raw == string(s) || raw == severityNames[s] {
+			return s, true
+		}
+	}
+	return "", false
+}
+
+// Name 返回严重程度的中文名称。
+func (s Severity) Name() string {
+	if name, ok := severityNames[s]; ok {
+		return name
+	}
+	return string(s)
+}
+
+// Label 返回严重程度的中英文显示（如 "紧急 / Critical"）。
+func (s Severity) Label() string {
+	if s == "" {
+		return ""
+	}
+	en := string(s)
+	return s.Name() + " / " + strings.ToUpper(en[:1]) + en[1:]
+}
+
+// CriticalActions 定义紧急问题的额外处理（由 config.yaml 的 severity.critical 配置加载）。
+type CriticalActions struct {
+	Mentions []string `mapstructure:"mentions"` // 摘要中额外 @ 的负责人（open_id）
+	Pin      bool     `mapstructure:"pin"`      // 在群内置顶摘要消息
+}
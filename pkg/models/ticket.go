@@ -0,0 +1,367 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TicketStatus 表示工单状态。
+type TicketStatus string
+
+const (
+	TicketQueued     TicketStatus = "queued"      // 非工作时间已受理，上班后提交（摘要尚未发出）
+	TicketOpen       TicketStatus = "open"        // 已提交，待处理
+	TicketInProgress TicketStatus = "in_progress" // 已认领 / 已指派，处理中
+	TicketResolved   TicketStatus = "resolved"    // 已解决
+	TicketMerged     TicketStatus = "merged"      // 作为重复问题合并到其他工单
+)
+
+// SLA 考核项。
+const (
+	SLAFirstResponse = "first_response" // 首次响应
+	SLAResolution    = "resolution"     // 解决
+)
+
+// TicketPriorities 是工单可选的优先级（从高到低）。
+var TicketPriorities = []string{"P0", "P1", "P2", "P3"}
+
+// Ticket 表示转人工后持久保存的工单。
+type Ticket struct {
+	ID       string           `json:"id"`        // 工单号（如 T000123）
+	ChatID   string           `json:"chat_id"`   // 用户私聊 chatID
+	UserID   string           `json:"user_id"`   // 用户 open_id
+	UserName string           `json:"user_name"` // 用户名称
+	Mode     ConversationMode `json:"mode"`      // issue / suggestion
+
+	// 提交时收集的信息
+	CollectedInfo  map[string]string `json:"collected_info,omitempty"`
+	NormalizedInfo map[string]string `json:"normalized_info,omitempty"`
+	SuggestionText string            `json:"suggestion_text,omitempty"`
+	Files          []FileInfo        `json:"files,omitempty"`
+
+	// 转人工群组中的话题根消息（群组、标题和额外 @ 的人由路由规则决定）
+	GroupID   string   `json:"group_id"`
+	RootMsgID string   `json:"root_msg_id,omitempty"`
+	Route     string   `json:"route,omitempty"`    // 命中的路由名称，空为默认群组
+	Title     string   `json:"title,omitempty"`    // 路由配置的话题标题，空时按模式使用默认标题
+	Mentions  []string `json:"mentions,omitempty"` // 摘要中额外 @ 的人
+	OnCall    string   `json:"oncall,omitempty"`   // 提交时的值班人员（摘要中 @）
+	Severity  Severity `json:"severity,omitempty"` // 提交时自动分级的严重程度（标题中显示）
+
+	// 技术支持处理状态（话题内 @机器人 命令维护）
+	Status     TicketStatus `json:"status"`
+	Assignee   string       `json:"assignee,omitempty"` // 负责人 open_id
+	Priority   string       `json:"priority,omitempty"` // P0 ~ P3
+	Tags       []string     `json:"tags,omitempty"`
+	ResolvedAt time.Time    `json:"resolved_at,omitempty"`
+	ResolvedBy string       `json:"resolved_by,omitempty"` // 标记解决的技术支持 open_id
+
+	// SLA 计时（首次响应为技术支持在话题内的首条消息，提醒 / 超时状态由定时任务维护）
+	FirstResponseAt time.Time `json:"first_response_at,omitempty"`
+	SLAStart        time.Time `json:"sla_start,omitempty"`    // SLA 起算时间（非工作时间暂缓的工单为发出时间），空为 CreatedAt
+	SLAReminded     []string  `json:"sla_reminded,omitempty"` // 已提醒的 SLA 项
+	SLABreached     []string  `json:"sla_breached,omitempty"` // 已超时的 SLA 项
+	ReopenedAt      time.Time `json:"reopened_at,omitempty"`  // 最近一次重新打开的时间，解决时限从此重新计时
+
+	// 重复问题合并：重复工单记录主工单，主工单记录所有重复工单
+	MergedInto string   `json:"merged_into,omitempty"`
+	Duplicates []string `json:"duplicates,omitempty"`
+
+	// 解决后用户的满意度评价
+	CSAT *CSATResponse `json:"csat,omitempty"`
+
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CSATResponse 是用户对已解决工单的满意度评价。
+type CSATResponse struct {
+	Rating      int       `json:"rating"`            // 1 ~ 5
+	Comment     string    `json:"comment,omitempty"` // 可选评论
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// CSATMaxRating 是满意度评分的最高分。
+const CSATMaxRating = 5
+
+// CSATStars 返回评分的星级显示（如 ★★★★☆）。
+func CSATStars(rating int) string {
+	if rating < 0 {
+		rating = 0
+	}
+	if rating > CSATMaxRating {
+		rating = CSATMaxRating
+	}
+	return strings.Repeat("★", rating) + strings.Repeat("☆", CSATMaxRating-rating)
+}
+
+// Engineer 返回工单的处理人（负责人，未指派时为标记解决的人），用于满意度统计。
+func (t *Ticket) Engineer() string {
+	if t.Assignee != "" {
+		return t.Assignee
+	}
+	return t.ResolvedBy
+}
+
+// SupplementRequest 是技术支持在工单话题内发起的补充信息请求。
+type SupplementRequest struct {
+	TicketID    string   `json:"ticket_id"`
+	Fields      []string `json:"fields,omitempty"` // 请求补充的字段 key
+	Files       bool     `json:"files,omitempty"`  // 是否请求日志 / 录屏等附件
+	RequestedBy string   `json:"requested_by"`     // 发起请求的技术支持 open_id
+}
+
+// SupplementFilesName 是补充请求中附件的显示名称。
+const SupplementFilesName = "日志 / 录屏等附件 / Log or recording files"
+
+// GetSupplementSummary 返回补充信息会话中请求字段的值和附件（用于追加到工单话题）。
+func (c *Conversation) GetSupplementSummary() string {
+	if c.Supplement == nil {
+		return ""
+	}
+	var sb strings.Builder
+	for _, key := range c.Supplement.Fields {
+		field, ok := FindField(key)
+		if !ok {
+			continue
+		}
+		if val := c.summaryValue(key); val != "" {
+			sb.WriteString(fmt.Sprintf("【%s】%s\n", field.ShortName, val))
+		}
+	}
+	if c.HasFiles() {
+		sb.WriteString(fmt.Sprintf("【附件】%d 个（见话题内附件）\n", len(c.Files)))
+	}
+	return sb.String()
+}
+
+// ApplySupplement 将补充信息会话中的字段值和附件合并到工单。
+func (t *Ticket) ApplySupplement(conv *Conversation) {
+	if conv.Supplement == nil {
+		return
+	}
+	if t.CollectedInfo == nil {
+		t.CollectedInfo = make(map[string]string)
+	}
+	if t.NormalizedInfo == nil {
+		t.NormalizedInfo = make(map[string]string)
+	}
+	for _, key := range conv.Supplement.Fields {
+		if val := conv.CollectedInfo[key]; val != "" {
+			t.CollectedInfo[key] = val
+			if norm := conv.NormalizedInfo[key]; norm != "" {
+				t.NormalizedInfo[key] = norm
+			} else {
+				delete(t.NormalizedInfo, key)
+			}
+		}
+	}
+	t.Files = append(t.Files, conv.Files...)
+	t.UpdatedAt = time.Now()
+}
+
+// NewTicket 根据会话创建待处理的工单（不含工单号）。
+func NewTicket(conv *Conversation) *Ticket {
+	now := time.Now()
+	return &Ticket{
+		ChatID:         conv.ChatID,
+		UserID:         conv.SenderID,
+		UserName:       conv.SenderName,
+		Mode:           conv.Mode,
+		CollectedInfo:  conv.CollectedInfo,
+		NormalizedInfo: conv.NormalizedInfo,
+		SuggestionText: conv.SuggestionText,
+		Files:          conv.Files,
+		Status:         TicketOpen,
+		CreatedAt:      now,
+		UpdatedAt:      now,
+	}
+}
+
+// Draft 将工单内容还原为会话（用于复用摘要格式）。
+func (t *Ticket) Draft() *Conversation {
+	return &Conversation{
+		ChatID:         t.ChatID,
+		SenderID:       t.UserID,
+		SenderName:     t.UserName,
+		Mode:           t.Mode,
+		CollectedInfo:  t.CollectedInfo,
+		NormalizedInfo: t.NormalizedInfo,
+		SuggestionText: t.SuggestionText,
+		Files:          t.Files,
+		CreatedAt:      t.CreatedAt,
+		UpdatedAt:      t.CreatedAt,
+	}
+}
+
+// SerialNumbers 返回工单中填写的序列号（sn 校验字段，优先取标准值），用于按 SN 查询。
+func (t *Ticket) SerialNumbers() []string {
+	var sns []string
+	for _, field := range allFields {
+		if field.Validate != ValidateSN {
+			continue
+		}
+		sn := t.NormalizedInfo[field.Key]
+		if sn == "" {
+			sn = NormalizeSN(t.CollectedInfo[field.Key])
+		}
+		if sn != "" {
+			sns = append(sns, sn)
+		}
+	}
+	return sns
+}
+
+// ShortTitle 返回工单的简短标题：建议反馈取建议内容，问题反馈取第一个字段（问题描述），
+// 超过 maxRunes 个字符时截断。
+func (t *Ticket) ShortTitle(maxRunes int) string {
+	text := t.SuggestionText
+	if t.Mode != ModeSuggestion && len(allFields) > 0 {
+		text = t.CollectedInfo[allFields[0].Key]
+	}
+	text = strings.Join(strings.Fields(text), " ")
+	if text == "" {
+		return "（无描述）"
+	}
+	if runes := []rune(text); maxRunes > 0 && len(runes) > maxRunes {
+		return string(runes[:maxRunes]) + "…"
+	}
+	return text
+}
+
+// StatusLabel 返回工单状态的双语显示名。
+func (t *Ticket) StatusLabel() string {
+	switch t.Status {
+	case TicketQueued:
+		return "已受理，上班后提交 / Queued"
+	case TicketOpen:
+		return "待处理 / Open"
+	case TicketInProgress:
+		return "处理中 / In progress"
+	case TicketResolved:
+		return "已解决 / Resolved"
+	case TicketMerged:
+		return "已合并到 " + t.MergedInto + " / Merged"
+	}
+	return string(t.Status)
+}
+
+// IsClosed 判断工单是否已结束（已解决或已合并），结束的工单不再计入 SLA。
+func (t *Ticket) IsClosed() bool {
+	return t.Status == TicketResolved || t.Status == TicketMerged
+}
+
+// Reopen 将已解决的工单重新打开：有负责人时为处理中，否则为待处理。
+// 解决时限从重新打开时重新计时，并清除该项的提醒 / 超时记录。
+func (t *Ticket) Reopen() {
+	t.Status = TicketOpen
+	if t.Assignee != "" {
+		t.Status = TicketInProgress
+	}
+	t.ResolvedAt = time.Time{}
+	t.ResolvedBy = ""
+	t.UpdatedAt = time.Now()
+	t.ReopenedAt = t.UpdatedAt
+	t.SLAReminded = removeItem(t.SLAReminded, SLAResolution)
+	t.SLABreached = removeItem(t.SLABreached, SLAResolution)
+}
+
+// AddDuplicate 记录合并到本工单的重复工单，返回是否为首次记录。
+func (t *Ticket) AddDuplicate(id string) bool {
+	return addUnique(&t.Duplicates, id)
+}
+
+// AddTag 添加标签（已存在时忽略），返回是否添加。
+func (t *Ticket) AddTag(tag string) bool {
+	for _, existing := range t.Tags {
+		if strings.EqualFold(existing, tag) {
+			return false
+		}
+	}
+	t.Tags = append(t.Tags, tag)
+	return true
+}
+
+// RemoveTag 删除标签，返回是否删除。
+func (t *Ticket) RemoveTag(tag string) bool {
+	for i, existing := range t.Tags {
+		if strings.EqualFold(existing, tag) {
+			t.Tags = append(t.Tags[:i], t.Tags[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// MarkSLAReminded 记录 SLA 项已提醒，返回是否为首次记录。
+func (t *Ticket) MarkSLAReminded(kind string) bool {
+	return addUnique(&t.SLAReminded, kind)
+}
+
+// SLAClockStart 返回 SLA 项的起算时间（重新打开过的工单，解决时限从最近一次重新打开起算）。
+func (t *Ticket) SLAClockStart(kind string) time.Time {
+	if kind == SLAResolution && !t.ReopenedAt.IsZero() {
+		return t.ReopenedAt
+	}
+	if !t.SLAStart.IsZero() {
+		return t.SLAStart
+	}
+	return t.CreatedAt
+}
+
+// MarkSLABreached 记录 SLA 项已超时，返回是否为首次记录。
+func (t *Ticket) MarkSLABreached(kind string) bool {
+	return addUnique(&t.SLABreached, kind)
+}
+
+// SLABreachedFor 判断 SLA 项是否已超时。
+func (t *Ticket) SLABreachedFor(kind string) bool {
+	for _, k := range t.SLABreached {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
+
+// addUnique 向 list 中添加 s（已存在时忽略），返回是否添加。
+func addUnique(list *[]string, s string) bool {
+	for _, item := range *list {
+		if item == s {
+			return false
+		}
+	}
+	*list = append(*list, s)
+	return true
+}
+
+// removeItem 返回去掉 s 后的 list。
+func removeItem(list []string, s string) []string {
+	kept := list[:0]
+	for _, item := range list {
+		if item != s {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// NormalizePriority 将 "p1"、"1" 等写法标准化为 TicketPriorities 中的值。
+func NormalizePriority(raw string) (string, bool) {
+	p := strings.ToUpper(strings.TrimSpace(raw))
+	if !strings.HasPrefix(p, "P") {
+		p = "P" + p
+	}
+	for _, valid := range TicketPriorities {
+		if p == valid {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// NormalizeSN 去除空白并转大写（与 sn 字段校验的标准化一致）。
+func NormalizeSN(raw string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(raw), ""))
+}
@@ -30,30 +30,127 @@ type FileInfo struct {
 	FileName  string `json:"file_name"`  // 文件名
 }
 
-// FieldDef 定义一个信息字段。
+// FieldDef 定义一个信息字段（由 config.yaml 的 fields 配置加载）。
 type FieldDef struct {
-	Key       string // JSON key / CollectedInfo key
-	Name      string // 用户端双语显示名
-	ShortName string // 群组摘要中文短名（用于【】标题）
+	Key         string   `mapstructure:"key"`         // JSON key / CollectedInfo key
+	Name        string   `mapstructure:"name"`        // 用户端双语显示名
+	ShortName   string   `mapstructure:"short_name"`  // 群组摘要中文短名（用于【】标题）
+	Required    *bool    `mapstructure:"required"`    // 是否必填（false 或未配置表示可选，填了就记录，不填不阻塞提交）
+	Description string   `mapstructure:"description"` // 提供给 LLM 的字段说明（英文）
+	Examples    []string `mapstructure:"examples"`    // 示例值（用于 LLM Prompt）
+	Aliases     []string `mapstructure:"aliases"`     // 用户引用该字段时可能使用的其他叫法（如 "眼镜"、"glasses"）
+
+	// 校验与标准化（可选）
+	Validate string       `mapstructure:"validate"` // 校验规则：version / sn / enum / regex / datetime，空表示不校验
+	Pattern  string       `mapstructure:"pattern"`  // 正则表达式（sn / regex 使用）
+	Enum     []EnumOption `mapstructure:"enum"`     // 枚举选项（enum 使用）
+
+	// RequiredIf 条件必填规则：配置后仅当任一条件满足时才必填（如戒指字段仅在用户使用戒指时必填）。
+	RequiredIf []FieldCondition `mapstructure:"required_if"`
 }
 
-// RequiredFields 定义问题反馈模式需要收集的必填信息。
-var RequiredFields = []FieldDef{
-	{"issue", "问题描述 / Issue Description", "问题描述"},
-	{"occur_time", "发生时间 / Time of Occurrence", "发生时间"},
-	{"reproducible", "是否必现 / Reproducible?", "是否必现"},
-	{"app_version", "应用版本 / App Version", "应用版本"},
-	{"glasses_version", "眼镜版本 / Glasses Firmware", "眼镜版本"},
-	{"glasses_sn", "眼镜SN号 / Glasses SN", "眼镜SN号"},
-	{"ring_version", "戒指版本 / Ring Firmware", "戒指版本"},
-	{"ring_sn", "戒指SN号 / Ring SN", "戒指SN号"},
-	{"phone_model", "手机型号 / Phone Model", "手机型号"},
-	{"phone_os", "手机系统版本 / Phone OS Version", "手机系统版本"},
+// FieldCondition 定义一条条件必填规则，同一条件内的各项需同时满足。
+type FieldCondition struct {
+	Field    string   `mapstructure:"field"`    // 依据的字段 key
+	Equals   []string `mapstructure:"equals"`   // 字段值（优先取标准值）等于其中之一（大小写不敏感）
+	Contains []string `mapstructure:"contains"` // 字段原文包含其中任一关键词（大小写不敏感）
+	Present  *bool    `mapstructure:"present"`  // true 要求字段已填写，false 要求字段未填写
 }
 
-// OptionalFields 定义可选信息字段（用户填了就记录，不填不阻塞提交）。
-var OptionalFields = []FieldDef{
-	{"vpn", "是否使用VPN / Using VPN?", "是否使用VPN"},
+// IsRequired 判断字段是否为必填字段（条件必填字段条件满足时才要求填写）。
+func (f FieldDef) IsRequired() bool {
+	return f.Required != nil && *f.Required
+}
+
+// IsConditional 判断字段是否为条件必填。
+func (f FieldDef) IsConditional() bool {
+	return len(f.RequiredIf) > 0
+}
+
+// 字段校验规则。
+const (
+	ValidateVersion  = "version"  // 语义化版本号，标准化为 MAJOR.MINOR.PATCH
+	ValidateSN       = "sn"       // 序列号：去除空白、转大写后需匹配 Pattern
+	ValidateEnum     = "enum"     // 枚举：按同义词归一到 EnumOption.Value
+	ValidateRegex    = "regex"    // 正则：需包含 Pattern 的匹配，标准化为匹配到的部分
+	ValidateDatetime = "datetime" // 时间：按消息发送时间和用户时区解析为 RFC3339
+)
+
+// EnumOption 定义一个枚举值及其同义词。
+type EnumOption struct {
+	Value    string   `mapstructure:"value"`    // 标准值（如 yes / no / sometimes）
+	Synonyms []string `mapstructure:"synonyms"` // 同义词（中英文，大小写不敏感）
+}
+
+var (
+	// allFields 按配置顺序保存全部字段定义。
+	allFields []FieldDef
+	// RequiredFields 定义问题反馈模式需要收集的必填信息。
+	RequiredFields []FieldDef
+	// OptionalFields 定义可选信息字段（用户填了就记录，不填不阻塞提交）。
+	OptionalFields []FieldDef
+)
+
+// SetFieldSchema 设置字段定义（启动时由配置加载后调用一次）。
+func SetFieldSchema(fields []FieldDef) {
+	allFields = append([]FieldDef(nil), fields...)
+	RequiredFields = nil
+	OptionalFields = nil
+	for _, f := range allFields {
+		if f.IsRequired() {
+			RequiredFields = append(RequiredFields, f)
+		} else {
+			OptionalFields = append(OptionalFields, f)
+		}
+	}
+}
+
+// AllFields 返回全部字段定义（按配置顺序）。
+func AllFields() []FieldDef {
+	return allFields
+}
+
+// FindField 根据 key 查找字段定义。
+func FindField(key string) (FieldDef, bool) {
+	for _, f := range allFields {
+		if f.Key == key {
+			return f, true
+		}
+	}
+	return FieldDef{}, false
+}
+
+// FieldAliases 返回字段可被用户引用的名称：key、短名、显示名中的中英文部分以及配置的别名。
+func (f FieldDef) FieldAliases() []string {
+	aliases := append([]string{f.Key, f.ShortName}, f.Aliases...)
+	for _, part := range strings.Split(f.Name, "/") {
+		if part = strings.TrimSpace(part); part != "" {
+			aliases = append(aliases, strings.TrimSuffix(part, "?"))
+		}
+	}
+	return aliases
+}
+
+// MatchField 在文本中查找被提及的字段（按 FieldAliases 匹配，优先最长的名称）。
+func MatchField(text string, candidates []FieldDef) (FieldDef, bool) {
+	var best FieldDef
+	bestLen := 0
+	for _, f := range candidates {
+		for _, alias := range f.FieldAliases() {
+			if len(alias) > bestLen && ContainsKeyword(text, alias) {
+				best, bestLen = f, len(alias)
+			}
+		}
+	}
+	return best, bestLen > 0
+}
+
+// PendingValue 表示置信度较低、等待用户确认的提取值。
+type PendingValue struct {
+	Value      string  `json:"value"`                // 用户原文值
+	Normalized string  `json:"normalized,omitempty"` // 校验后的标准值
+	Confidence float64 `json:"confidence"`           // LLM 给出的置信度
+	Source     string  `json:"source,omitempty"`     // 值在用户消息中的原文片段
 }
 
 // Conversation 表示用户会话。
@@ -67,16 +164,40 @@ type Conversation struct {
 	Mode ConversationMode `json:"mode,omitempty"` // issue / suggestion
 
 	// 信息收集状态
-	CollectedInfo map[string]string `json:"collected_info,omitempty"` // 已收集的信息
-	Files         []FileInfo        `json:"files,omitempty"`          // 用户上传的文件列表
+	CollectedInfo  map[string]string `json:"collected_info,omitempty"`  // 已收集的信息（用户原文）
+	NormalizedInfo map[string]string `json:"normalized_info,omitempty"` // 校验后的标准值（与原文并存）
+	Files          []FileInfo        `json:"files,omitempty"`           // 用户上传的文件列表
+
+	// 置信度较低、等待用户确认的值（确认前不计入 CollectedInfo）
+	PendingInfo map[string]PendingValue `json:"pending_info,omitempty"`
 
 	// 建议内容（建议模式下使用）
 	SuggestionText string `json:"suggestion_text,omitempty"`
 
+	// 提交确认：信息收集完毕后发送确认卡片，用户点击提交（或超时自动提交）后才转人工
+	ConfirmCardID   string    `json:"confirm_card_id,omitempty"`  // 确认卡片消息 ID
+	ConfirmDeadline time.Time `json:"confirm_deadline,omitempty"` // 自动提交时间（零值表示不自动提交）
+
+	// 表单卡片消息 ID（已主动发送过表单时不再重复发送）
+	FormCardID string `json:"form_card_id,omitempty"`
+
+	// 技术支持请求补充信息时非空：只收集请求的字段 / 附件，完成后追加到原工单话题
+	Supplement *SupplementRequest `json:"supplement,omitempty"`
+
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 }
 
+// AwaitingConfirmation 判断会话是否在等待用户确认提交。
+func (c *Conversation) AwaitingConfirmation() bool {
+	return c.ConfirmCardID != ""
+}
+
+// HasDraft 判断会话中是否已有用户提供的内容（字段、待确认值、附件或建议）。
+func (c *Conversation) HasDraft() bool {
+	return len(c.CollectedInfo) > 0 || len(c.PendingInfo) > 0 || len(c.Files) > 0 || c.SuggestionText != ""
+}
+
 // AddMessage 添加消息到会话。
 func (c *Conversation) AddMessage(role, content string) {
 	c.Messages = append(c.Messages, Message{
@@ -96,6 +217,53 @@ func (c *Conversation) SetCollectedInfo(key, value string) {
 	c.UpdatedAt = time.Now()
 }
 
+// SetNormalizedInfo 设置字段的标准值，value 为空时删除。
+func (c *Conversation) SetNormalizedInfo(key, value string) {
+	if value == "" {
+		delete(c.NormalizedInfo, key)
+		return
+	}
+	if c.NormalizedInfo == nil {
+		c.NormalizedInfo = make(map[string]string)
+	}
+	c.NormalizedInfo[key] = value
+}
+
+// MarkUnresolved 标记字段原文未能解析为标准值（如发生时间不够具体），完整性判断时仍视为缺失。
+func (c *Conversation) MarkUnresolved(key string) {
+	if c.NormalizedInfo == nil {
+		c.NormalizedInfo = make(map[string]string)
+	}
+	c.NormalizedInfo[key] = ""
+}
+
+// SetPendingInfo 记录待确认的值。
+func (c *Conversation) SetPendingInfo(key string, pv PendingValue) {
+	if c.PendingInfo == nil {
+		c.PendingInfo = make(map[string]PendingValue)
+	}
+	c.PendingInfo[key] = pv
+	c.UpdatedAt = time.Now()
+}
+
+// ConfirmPendingInfo 将待确认的值写入已收集信息。
+func (c *Conversation) ConfirmPendingInfo(key string) bool {
+	pv, ok := c.PendingInfo[key]
+	if !ok {
+		return false
+	}
+	c.SetCollectedInfo(key, pv.Value)
+	c.SetNormalizedInfo(key, pv.Normalized)
+	delete(c.PendingInfo, key)
+	return true
+}
+
+// DiscardPendingInfo 丢弃待确认的值。
+func (c *Conversation) DiscardPendingInfo(key string) {
+	delete(c.PendingInfo, key)
+	c.UpdatedAt = time.Now()
+}
+
 // GetCollectedInfo 获取已收集的信息。
 func (c *Conversation) GetCollectedInfo(key string) (string, bool) {
 	if c.CollectedInfo == nil {
@@ -116,28 +284,150 @@ func (c *Conversation) HasFiles() bool {
 	return len(c.Files) > 0
 }
 
-// IsInfoComplete 检查是否所有必填信息都已收集（仅在问题反馈模式下使用）。
+// IsFieldRequired 判断字段对当前会话是否必填：非条件字段按 Required，条件字段任一条件满足即必填。
+func (c *Conversation) IsFieldRequired(field FieldDef) bool {
+	if !field.IsRequired() {
+		return false
+	}
+	if !field.IsConditional() {
+		return true
+	}
+	for _, cond := range field.RequiredIf {
+		if c.matchCondition(cond) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchCondition 判断会话当前信息是否满足条件。
+func (c *Conversation) matchCondition(cond FieldCondition) bool {
+	raw := strings.TrimSpace(c.CollectedInfo[cond.Field])
+	if cond.Present != nil && *cond.Present != (raw != "") {
+		return false
+	}
+	if len(cond.Equals) > 0 {
+		val := c.NormalizedInfo[cond.Field]
+		if val == "" {
+			val = raw
+		}
+		if !containsFold(cond.Equals, val) {
+			return false
+		}
+	}
+	if len(cond.Contains) > 0 {
+		found := false
+		for _, kw := range cond.Contains {
+			if ContainsKeyword(raw, kw) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// ContainsKeyword 判断文本是否包含关键词（大小写不敏感）。
+// 英文关键词按单词边界匹配（避免 "ring" 命中 "during"），中文关键词按子串匹配。
+func ContainsKeyword(text, keyword string) bool {
+	text = strings.ToLower(text)
+	keyword = strings.ToLower(strings.TrimSpace(keyword))
+	if keyword == "" {
+		return false
+	}
+	for offset := 0; ; {
+		idx := strings.Index(text[offset:], keyword)
+		if idx < 0 {
+			return false
+		}
+		start := offset + idx
+		end := start + len(keyword)
+		if isWordBoundary(text, start-1, keyword[0]) && isWordBoundary(text, end, keyword[len(keyword)-1]) {
+			return true
+		}
+		offset = start + 1
+	}
+}
+
+// isWordBoundary 判断 text[pos] 处是否构成单词边界；edge 为关键词在该侧的字节，非 ASCII 字母数字时无需边界。
+func isWordBoundary(text string, pos int, edge byte) bool {
+	if !isASCIIAlnum(edge) || pos < 0 || pos >= len(text) {
+		return true
+	}
+	return !isASCIIAlnum(text[pos])
+}
+
+// isASCIIAlnum 判断字节是否为 ASCII 字母或数字。
+func isASCIIAlnum(b byte) bool {
+	return (b >= 'a' && b <= 'z') || (b >= 'A' && b <= 'Z') || (b >= '0' && b <= '9')
+}
+
+// containsFold 判断 list 中是否有与 val 相等的项（大小写不敏感）。
+func containsFold(list []string, val string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, val) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsInfoComplete 检查是否所有必填信息都已收集（仅在问题反馈模式下使用，考虑条件必填规则）。
+// 补充信息会话只检查技术支持请求的字段和附件。
 func (c *Conversation) IsInfoComplete() bool {
+	if c.Supplement != nil {
+		return len(c.GetMissingFields()) == 0
+	}
 	if c.CollectedInfo == nil {
 		return false
 	}
 	for _, field := range RequiredFields {
-		if val, ok := c.CollectedInfo[field.Key]; !ok || val == "" {
+		if !c.IsFieldRequired(field) {
+			continue
+		}
+		if !c.hasFieldValue(field) {
 			return false
 		}
 	}
 	return true
 }
 
-// GetMissingFields 获取缺失的必填信息列表（返回显示名称）。
+// hasFieldValue 判断字段是否已收集：有校验规则的字段标记为未解析时（如发生时间描述不明确）仍视为缺失；
+// 没有标准值记录的字段（如校验规则上线前创建的草稿）按原文判断。
+func (c *Conversation) hasFieldValue(field FieldDef) bool {
+	if c.CollectedInfo[field.Key] == "" {
+		return false
+	}
+	if field.Validate == "" {
+		return true
+	}
+	normalized, ok := c.NormalizedInfo[field.Key]
+	return !ok || normalized != ""
+}
+
+// GetMissingFields 获取缺失的必填信息列表（返回显示名称，不含当前不适用的条件字段）。
+// 补充信息会话返回尚未提供的请求字段和附件。
 func (c *Conversation) GetMissingFields() []string {
 	var missing []string
+	if c.Supplement != nil {
+		for _, key := range c.Supplement.Fields {
+			if field, ok := FindField(key); ok && !c.hasFieldValue(field) {
+				missing = append(missing, field.Name)
+			}
+		}
+		if c.Supplement.Files && !c.HasFiles() {
+			missing = append(missing, SupplementFilesName)
+		}
+		return missing
+	}
 	for _, field := range RequiredFields {
-		if c.CollectedInfo == nil {
-			missing = append(missing, field.Name)
+		if !c.IsFieldRequired(field) {
 			continue
 		}
-		if val, ok := c.CollectedInfo[field.Key]; !ok || val == "" {
+		if !c.hasFieldValue(field) {
 			missing = append(missing, field.Name)
 		}
 	}
@@ -158,13 +448,13 @@ func (c *Conversation) GetInfoSummary() string {
 		sb.WriteString("【类型】问题反馈\n\n")
 		// 必填字段：有值才展示
 		for _, field := range RequiredFields {
-			if val, ok := c.CollectedInfo[field.Key]; ok && val != "" {
+			if val := c.summaryValue(field.Key); val != "" {
 				sb.WriteString(fmt.Sprintf("【%s】%s\n", field.ShortName, val))
 			}
 		}
 		// 可选字段：有值才展示
 		for _, field := range OptionalFields {
-			if val, ok := c.CollectedInfo[field.Key]; ok && val != "" {
+			if val := c.summaryValue(field.Key); val != "" {
 				sb.WriteString(fmt.Sprintf("【%s】%s\n", field.ShortName, val))
 			}
 		}
@@ -177,6 +467,18 @@ func (c *Conversation) GetInfoSummary() string {
 	return sb.String()
 }
 
+// summaryValue 返回群组摘要中展示的字段值：有标准值且与原文不同时展示「标准值（原文：xxx）」。
+func (c *Conversation) summaryValue(key string) string {
+	raw := c.CollectedInfo[key]
+	if raw == "" {
+		return ""
+	}
+	if norm := c.NormalizedInfo[key]; norm != "" && norm != raw {
+		return fmt.Sprintf("%s（原文：%s）", norm, raw)
+	}
+	return raw
+}
+
 // GetUserSummary 获取用于展示给用户的信息摘要（只显示已填写的字段）。
 func (c *Conversation) GetUserSummary() string {
 	var sb strings.Builder